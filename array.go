@@ -0,0 +1,108 @@
+package easyjson
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Insert adds value before index i, like Python's list.insert. Negative
+// indexes count from the end, and indexes past either end insert at that
+// end.
+func (jv *JSONValue) Insert(i int, value interface{}) error {
+	arr, ok := jv.data.([]interface{})
+	if !ok {
+		return fmt.Errorf("cannot insert into non-array type")
+	}
+	jv.data = slices.Insert(arr, clampIndex(i, len(arr)), value)
+	jv.writeBack(arr)
+	return nil
+}
+
+// Pop removes and returns the last element of an array
+func (jv *JSONValue) Pop() (*JSONValue, error) {
+	return jv.PopAt(-1)
+}
+
+// PopAt removes and returns the element at index i, which may be negative
+// to count from the end
+func (jv *JSONValue) PopAt(i int) (*JSONValue, error) {
+	arr, ok := jv.data.([]interface{})
+	if !ok {
+		return &JSONValue{data: nil}, fmt.Errorf("cannot pop from non-array type")
+	}
+	index, ok := resolveIndex(i, len(arr))
+	if !ok {
+		return &JSONValue{data: nil}, fmt.Errorf("index out of range")
+	}
+	value := arr[index]
+	jv.data = slices.Delete(arr, index, index+1)
+	jv.writeBack(arr)
+	return &JSONValue{data: value}, nil
+}
+
+// RemoveWhere removes every element for which pred returns true and
+// reports how many were removed
+func (jv *JSONValue) RemoveWhere(pred func(v *JSONValue) bool) (int, error) {
+	arr, ok := jv.data.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("cannot remove from non-array type")
+	}
+	kept := arr[:0]
+	for i, item := range arr {
+		if !pred(&JSONValue{data: item, parent: jv, key: i}) {
+			kept = append(kept, item)
+		}
+	}
+	removed := len(arr) - len(kept)
+	clear(arr[len(kept):])
+	jv.data = kept
+	jv.writeBack(arr)
+	return removed, nil
+}
+
+// Splice removes deleteCount elements starting at start and inserts items
+// in their place, like JavaScript's Array.prototype.splice. It returns the
+// removed elements as an array. A negative start counts from the end and
+// out-of-range values are clamped.
+func (jv *JSONValue) Splice(start, deleteCount int, items ...interface{}) (*JSONValue, error) {
+	arr, ok := jv.data.([]interface{})
+	if !ok {
+		return &JSONValue{data: nil}, fmt.Errorf("cannot splice non-array type")
+	}
+	start = clampIndex(start, len(arr))
+	end := start + max(0, min(deleteCount, len(arr)-start))
+	removed := append([]interface{}{}, arr[start:end]...)
+	jv.data = slices.Replace(arr, start, end, items...)
+	jv.writeBack(arr)
+	return &JSONValue{data: removed}, nil
+}
+
+// Reverse reverses an array in place
+func (jv *JSONValue) Reverse() error {
+	arr, ok := jv.data.([]interface{})
+	if !ok {
+		return fmt.Errorf("cannot reverse non-array type")
+	}
+	slices.Reverse(arr)
+	return nil
+}
+
+// IndexOf returns the index of the first element deeply equal to value,
+// or -1. Numbers compare by value, so 1 matches 1.0.
+func (jv *JSONValue) IndexOf(value interface{}) int {
+	for i, item := range jv.elements() {
+		if equalValues(item, value, &EqualOptions{}) {
+			return i
+		}
+	}
+	return -1
+}
+
+// clampIndex resolves a possibly negative insertion point in an array of
+// length n, clamping it to [0, n]
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	return max(0, min(i, n))
+}
@@ -0,0 +1,125 @@
+package easyjson
+
+import (
+	"testing"
+)
+
+func TestNegativeIndexing(t *testing.T) {
+	jv, _ := Loads(`{"items": [1, 2, 3]}`)
+	items := jv.Get("items")
+
+	if items.Get(-1).AsInt() != 3 || items.Get(-3).AsInt() != 1 || !items.Get(-4).IsNull() {
+		t.Error("Get: wrong negative indexing")
+	}
+	if !items.Has(-3) || items.Has(-4) {
+		t.Error("Has: wrong negative indexing")
+	}
+	if err := items.Set(-1, 30); err != nil || items.Get(2).AsInt() != 30 {
+		t.Errorf("Set(-1): %v", err)
+	}
+	if err := items.Set(-4, 0); err == nil {
+		t.Error("Set(-4): expected index out of range")
+	}
+	if jv.Path("items.-2").AsInt() != 2 {
+		t.Error("Path: expected negative index support")
+	}
+	if err := jv.Get("items").Delete(-1); err != nil || dumps(t, jv) != `{"items":[1,2]}` {
+		t.Errorf("Delete(-1): got %s (%v)", dumps(t, jv), err)
+	}
+}
+
+func TestInsertPop(t *testing.T) {
+	jv, _ := Loads(`{"items": ["b", "d"]}`)
+	items := jv.Get("items")
+
+	items.Insert(1, "c")
+	items.Insert(0, "a")
+	items.Insert(-1, "c2")
+	items.Insert(100, "e")
+	items.Insert(-100, "start")
+	if got := dumps(t, jv); got != `{"items":["start","a","b","c","c2","d","e"]}` {
+		t.Errorf("Insert: got %s", got)
+	}
+
+	last, err := items.Pop()
+	if err != nil || last.AsString() != "e" {
+		t.Errorf("Pop: got %v (%v)", last, err)
+	}
+	second, _ := items.PopAt(1)
+	first, _ := items.PopAt(-5)
+	if second.AsString() != "a" || first.AsString() != "start" {
+		t.Errorf("PopAt: got %v and %v", second, first)
+	}
+	if got := dumps(t, jv); got != `{"items":["b","c","c2","d"]}` {
+		t.Errorf("PopAt: got %s", got)
+	}
+
+	if _, err := NewArray().Pop(); err == nil {
+		t.Error("Pop: expected error for empty array")
+	}
+	if _, err := New("x").PopAt(0); err == nil {
+		t.Error("PopAt: expected error for non-array")
+	}
+	if err := NewObject().Insert(0, 1); err == nil {
+		t.Error("Insert: expected error for non-array")
+	}
+}
+
+func TestRemoveWhereSplice(t *testing.T) {
+	jv, _ := Loads(`{"n": [1, 2, 3, 4, 5, 6]}`)
+	n := jv.Get("n")
+
+	removed, err := n.RemoveWhere(func(v *JSONValue) bool { return v.AsInt()%2 == 0 })
+	if err != nil || removed != 3 || dumps(t, jv) != `{"n":[1,3,5]}` {
+		t.Errorf("RemoveWhere: removed %d, got %s (%v)", removed, dumps(t, jv), err)
+	}
+
+	tests := []struct {
+		start, count int
+		items        []interface{}
+		removed      string
+		want         string
+	}{
+		{1, 1, []interface{}{"x", "y"}, `[2]`, `[1,"x","y",3,4]`},
+		{-2, 5, nil, `[3,4]`, `[1,2]`},
+		{10, 1, []interface{}{9}, `[]`, `[1,2,3,4,9]`},
+		{0, -1, []interface{}{0}, `[]`, `[0,1,2,3,4]`},
+	}
+	for _, tt := range tests {
+		arr := New([]interface{}{1, 2, 3, 4})
+		got, err := arr.Splice(tt.start, tt.count, tt.items...)
+		if err != nil || dumps(t, got) != tt.removed || dumps(t, arr) != tt.want {
+			t.Errorf("Splice(%d, %d): removed %s, got %s (%v)", tt.start, tt.count, dumps(t, got), dumps(t, arr), err)
+		}
+	}
+}
+
+func TestReverseIndexOf(t *testing.T) {
+	jv, _ := Loads(`[1, "two", {"a": [3]}, null, true]`)
+
+	for _, tt := range []struct {
+		value interface{}
+		want  int
+	}{
+		{1, 0},
+		{1.0, 0},
+		{"two", 1},
+		{map[string]interface{}{"a": []interface{}{3}}, 2},
+		{nil, 3},
+		{true, 4},
+		{"1", -1},
+		{map[string]interface{}{"a": []interface{}{4}}, -1},
+	} {
+		if got := jv.IndexOf(tt.value); got != tt.want {
+			t.Errorf("IndexOf(%v): expected %d, got %d", tt.value, tt.want, got)
+		}
+	}
+
+	jv.Reverse()
+	if got := dumps(t, jv); got != `[true,null,{"a":[3]},"two",1]` {
+		t.Errorf("Reverse: got %s", got)
+	}
+	if err := NewObject().Reverse(); err == nil {
+		t.Error("Reverse: expected error for non-array")
+	}
+}
@@ -0,0 +1,182 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonical serializes the JSONValue with the JSON Canonicalization Scheme
+// (RFC 8785): no whitespace, object keys sorted by their UTF-16 code
+// units, numbers formatted like ECMAScript's Number.prototype.toString and
+// strings escaped minimally. Equal documents always produce identical
+// bytes, which makes the output suitable for hashing and signing.
+func (jv *JSONValue) Canonical() ([]byte, error) {
+	return appendCanonical(nil, jv.data)
+}
+
+func appendCanonical(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case bool:
+		return strconv.AppendBool(buf, v), nil
+	case string:
+		return appendCanonicalString(buf, v)
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", v)
+		}
+		return appendCanonicalNumber(buf, f)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sortUTF16(keys)
+		buf = append(buf, '{')
+		for i, k := range keys {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			if buf, err = appendCanonicalString(buf, k); err != nil {
+				return nil, err
+			}
+			buf = append(buf, ':')
+			if buf, err = appendCanonical(buf, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	case []interface{}:
+		buf = append(buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			if buf, err = appendCanonical(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	}
+
+	if f, ok := toFloat64(value); ok {
+		return appendCanonicalNumber(buf, f)
+	}
+
+	if jv, ok := value.(*JSONValue); ok && jv != nil {
+		return appendCanonical(buf, jv.data)
+	}
+
+	// Other Go values are canonicalized through their encoding/json form
+	generic, err := genericValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return appendCanonical(buf, generic)
+}
+
+// sortUTF16 sorts keys by their UTF-16 code units, as RFC 8785 requires
+func sortUTF16(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		return slices.Compare(utf16.Encode([]rune(keys[i])), utf16.Encode([]rune(keys[j]))) < 0
+	})
+}
+
+func appendCanonicalString(buf []byte, s string) ([]byte, error) {
+	if !utf8.ValidString(s) {
+		return nil, fmt.Errorf("invalid UTF-8 in string %q", s)
+	}
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c == '\b':
+			buf = append(buf, `\b`...)
+		case c == '\t':
+			buf = append(buf, `\t`...)
+		case c == '\n':
+			buf = append(buf, `\n`...)
+		case c == '\f':
+			buf = append(buf, `\f`...)
+		case c == '\r':
+			buf = append(buf, `\r`...)
+		case c < 0x20:
+			buf = append(buf, `\u00`...)
+			buf = append(buf, hexDigits[c>>4], hexDigits[c&0xf])
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return append(buf, '"'), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendCanonicalNumber formats f like ECMAScript's Number.prototype.toString
+func appendCanonicalNumber(buf []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("unsupported number %v", f)
+	}
+	if f == 0 {
+		return append(buf, '0'), nil
+	}
+	if f < 0 {
+		buf = append(buf, '-')
+		f = -f
+	}
+
+	// The shortest round-tripping digits d1.d2...dk and exponent give
+	// f = digits × 10^(n-k)
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	i := strings.IndexByte(e, 'e')
+	mantissa, exp := e[:i], e[i+1:]
+	digits := mantissa[:1]
+	if len(mantissa) > 2 {
+		digits += mantissa[2:]
+	}
+	x, _ := strconv.Atoi(exp)
+	k, n := len(digits), x+1
+
+	switch {
+	case k <= n && n <= 21:
+		buf = append(buf, digits...)
+		for i := 0; i < n-k; i++ {
+			buf = append(buf, '0')
+		}
+	case 0 < n && n <= 21:
+		buf = append(buf, digits[:n]...)
+		buf = append(buf, '.')
+		buf = append(buf, digits[n:]...)
+	case -6 < n && n <= 0:
+		buf = append(buf, "0."...)
+		for i := 0; i < -n; i++ {
+			buf = append(buf, '0')
+		}
+		buf = append(buf, digits...)
+	default:
+		buf = append(buf, digits[0])
+		if k > 1 {
+			buf = append(buf, '.')
+			buf = append(buf, digits[1:]...)
+		}
+		buf = append(buf, 'e')
+		if n-1 >= 0 {
+			buf = append(buf, '+')
+		}
+		buf = strconv.AppendInt(buf, int64(n-1), 10)
+	}
+	return buf, nil
+}
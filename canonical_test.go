@@ -0,0 +1,131 @@
+package easyjson
+
+import (
+	"math"
+	"testing"
+)
+
+// Number vectors from RFC 8785, Appendix B
+func TestCanonicalNumbers(t *testing.T) {
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, test := range tests {
+		got, err := New(math.Float64frombits(test.bits)).Canonical()
+		if err != nil {
+			t.Errorf("%#016x: unexpected error %v", test.bits, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%#016x: expected %s, got %s", test.bits, test.want, got)
+		}
+	}
+
+	for _, bits := range []uint64{0x7fffffffffffffff, 0x7ff0000000000000} {
+		if _, err := New(math.Float64frombits(bits)).Canonical(); err == nil {
+			t.Errorf("%#016x: expected error", bits)
+		}
+	}
+}
+
+// Example from RFC 8785, Section 3.2.2
+func TestCanonicalExample(t *testing.T) {
+	input := `{
+  "numbers": [333333333.33333329, 1E30, 4.50,
+              2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+	want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+
+	jv, err := Loads(input)
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	got, err := jv.Canonical()
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+// Sorting example from RFC 8785, Section 3.2.3
+func TestCanonicalKeyOrder(t *testing.T) {
+	input := `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`
+	want := "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\"," +
+		"\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+
+	jv, err := Loads(input)
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	got, err := jv.Canonical()
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestCanonicalGoValues(t *testing.T) {
+	jv := New(map[string]interface{}{
+		"int":    1,
+		"int64":  int64(-20),
+		"float":  2.50,
+		"nested": map[string]interface{}{"b": []interface{}{uint8(3)}, "a": "<&>"},
+		"struct": struct {
+			Name string `json:"name"`
+		}{"x"},
+	})
+	want := `{"float":2.5,"int":1,"int64":-20,"nested":{"a":"<&>","b":[3]},"struct":{"name":"x"}}`
+
+	got, err := jv.Canonical()
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	if _, err := New("\xff").Canonical(); err == nil {
+		t.Error("Expected error for invalid UTF-8")
+	}
+}
@@ -0,0 +1,583 @@
+package easyjson
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CBOROptions configures how CBOR is read. The JSONValue model has no
+// binary or time type, so by default byte strings become base64 strings
+// and dates become RFC 3339 strings.
+type CBOROptions struct {
+	// KeepBinary keeps byte strings as []byte, which DumpCBOR writes back
+	// as byte strings
+	KeepBinary bool
+
+	// KeepTimestamps keeps dates as time.Time, which DumpCBOR writes back
+	// as epoch dates
+	KeepTimestamps bool
+}
+
+// CBOR major types, the top three bits of each item's initial byte
+const (
+	cborUint = iota
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// CBOR tag numbers with special handling
+const (
+	cborTagDateString = 0
+	cborTagEpochDate  = 1
+	cborTagBignum     = 2
+	cborTagNegBignum  = 3
+)
+
+// DumpCBOR encodes the value as CBOR (RFC 8949) using the core
+// deterministic encoding of section 4.2.1: lengths are definite,
+// arguments and floats use the shortest form that holds them, and map
+// keys are sorted by their encoded bytes, so equal values encode to the
+// same bytes. Integral numbers are written as integers, with bignums for
+// json.Number and *big.Int values beyond 64 bits. []byte values are
+// written as byte strings and time.Time values as epoch dates.
+func (jv *JSONValue) DumpCBOR() ([]byte, error) {
+	buf, err := appendCBOR(nil, jv.data)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// LoadCBOR decodes a single CBOR data item, following the conversion to
+// JSON in RFC 8949 section 6.1 where the model allows. Indefinite-length
+// strings, arrays and maps are accepted. Integers become float64 when
+// they fit in 53 bits, int64 or uint64 when they fit in 64, and
+// json.Number otherwise, as do bignums (tags 2 and 3). Date strings
+// (tag 0) are kept as they are and epoch dates (tag 1) become RFC 3339
+// strings in UTC. Other tags are dropped in favor of their content, and
+// undefined becomes null. NaN, infinities, other simple values and map
+// keys other than strings and integers are rejected.
+func LoadCBOR(data []byte, opts CBOROptions) (*JSONValue, error) {
+	d := &cborDecoder{data: data, opts: opts}
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos < len(d.data) {
+		return nil, d.errorAt(d.pos, "trailing data after CBOR item")
+	}
+	return &JSONValue{data: value}, nil
+}
+
+// appendCBOR writes the deterministic CBOR encoding of value to buf
+func appendCBOR(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xf6), nil
+	case bool:
+		if v {
+			return append(buf, 0xf5), nil
+		}
+		return append(buf, 0xf4), nil
+	case string:
+		v = strings.ToValidUTF8(v, "\uFFFD")
+		return append(appendCBORHead(buf, cborText, uint64(len(v))), v...), nil
+	case []byte:
+		return append(appendCBORHead(buf, cborBytes, uint64(len(v))), v...), nil
+	case float64:
+		return appendCBORFloat(buf, v)
+	case float32:
+		return appendCBORFloat(buf, float64(v))
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			if n, ok := new(big.Int).SetString(string(v), 10); ok {
+				return appendCBORInt(buf, n), nil
+			}
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return appendCBORFloat(buf, f)
+	case int:
+		return appendCBORInt(buf, big.NewInt(int64(v))), nil
+	case int8:
+		return appendCBORInt(buf, big.NewInt(int64(v))), nil
+	case int16:
+		return appendCBORInt(buf, big.NewInt(int64(v))), nil
+	case int32:
+		return appendCBORInt(buf, big.NewInt(int64(v))), nil
+	case int64:
+		return appendCBORInt(buf, big.NewInt(v)), nil
+	case uint:
+		return appendCBORHead(buf, cborUint, uint64(v)), nil
+	case uint8:
+		return appendCBORHead(buf, cborUint, uint64(v)), nil
+	case uint16:
+		return appendCBORHead(buf, cborUint, uint64(v)), nil
+	case uint32:
+		return appendCBORHead(buf, cborUint, uint64(v)), nil
+	case uint64:
+		return appendCBORHead(buf, cborUint, v), nil
+	case *big.Int:
+		if v == nil {
+			return append(buf, 0xf6), nil
+		}
+		return appendCBORInt(buf, v), nil
+	case big.Int:
+		return appendCBORInt(buf, &v), nil
+	case time.Time:
+		buf = appendCBORHead(buf, cborTag, cborTagEpochDate)
+		if v.Nanosecond() == 0 {
+			return appendCBORInt(buf, big.NewInt(v.Unix())), nil
+		}
+		return appendCBORFloat(buf, float64(v.Unix())+float64(v.Nanosecond())/1e9)
+	case []interface{}:
+		buf = appendCBORHead(buf, cborArray, uint64(len(v)))
+		var err error
+		for _, item := range v {
+			if buf, err = appendCBOR(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return cborKeyLess(keys[i], keys[j]) })
+		buf = appendCBORHead(buf, cborMap, uint64(len(v)))
+		var err error
+		for _, k := range keys {
+			buf, _ = appendCBOR(buf, k)
+			if buf, err = appendCBOR(buf, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case *JSONValue:
+		if v == nil {
+			return append(buf, 0xf6), nil
+		}
+		return appendCBOR(buf, v.data)
+	}
+	generic, err := genericValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return appendCBOR(buf, generic)
+}
+
+// cborKeyLess orders text keys by their encoded bytes: shorter keys
+// first, then bytewise
+func cborKeyLess(a, b string) bool {
+	a, b = strings.ToValidUTF8(a, "\uFFFD"), strings.ToValidUTF8(b, "\uFFFD")
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// appendCBORHead writes an initial byte and the shortest argument form
+// for n
+func appendCBORHead(buf []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, major|27), n)
+}
+
+// appendCBORInt writes n as an integer, or as a bignum when it needs
+// more than 64 bits
+func appendCBORInt(buf []byte, n *big.Int) []byte {
+	major, tag := byte(cborUint), uint64(cborTagBignum)
+	if n.Sign() < 0 {
+		// negative integers are encoded as -1 - n
+		n = new(big.Int).Not(n)
+		major, tag = cborNegative, cborTagNegBignum
+	}
+	if n.IsUint64() {
+		return appendCBORHead(buf, major, n.Uint64())
+	}
+	digits := n.Bytes()
+	buf = appendCBORHead(buf, cborTag, tag)
+	return append(appendCBORHead(buf, cborBytes, uint64(len(digits))), digits...)
+}
+
+// appendCBORFloat writes f as an integer when it is integral and fits
+// in 64 bits, and otherwise as the shortest of half, single and double
+// precision that holds it exactly
+func appendCBORFloat(buf []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("unsupported value: %v", f)
+	}
+	if f == math.Trunc(f) && !(f == 0 && math.Signbit(f)) && f >= -(1<<64) && f < 1<<64 {
+		n, _ := big.NewFloat(f).Int(nil)
+		return appendCBORInt(buf, n), nil
+	}
+	if f32 := float32(f); float64(f32) == f {
+		if h, ok := float16Bits(f32); ok {
+			return binary.BigEndian.AppendUint16(append(buf, 0xf9), h), nil
+		}
+		return binary.BigEndian.AppendUint32(append(buf, 0xfa), math.Float32bits(f32)), nil
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xfb), math.Float64bits(f)), nil
+}
+
+// float16Bits returns the half-precision encoding of f, reporting false
+// when f cannot be represented exactly
+func float16Bits(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127
+	mant := bits & 0x7fffff
+	switch {
+	case bits&0x7fffffff == 0:
+		return sign, true
+	case exp >= -14 && exp <= 15:
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mant>>13), true
+	case exp >= -24 && exp < -14:
+		// subnormal: the value is m * 2^-24 for a 10-bit m
+		full, shift := mant|0x800000, -exp-1
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	}
+	return 0, false
+}
+
+// float16Value decodes a half-precision float, as in RFC 8949 Appendix D
+func float16Value(h uint16) float64 {
+	exp, mant := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	opts  CBOROptions
+	depth int
+}
+
+func (d *cborDecoder) errorAt(offset int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s at offset %d", fmt.Sprintf(format, args...), offset)
+}
+
+// head reads an initial byte and its argument. info is the additional
+// information, 31 for an indefinite length or a break.
+func (d *cborDecoder) head() (major, info byte, arg uint64, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, d.errorAt(d.pos, "unexpected end of CBOR input")
+	}
+	c := d.data[d.pos]
+	major, info = c>>5, c&0x1f
+	d.pos++
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(d.data)-d.pos < size {
+			return 0, 0, 0, d.errorAt(len(d.data), "unexpected end of CBOR input")
+		}
+		for _, b := range d.data[d.pos : d.pos+size] {
+			arg = arg<<8 | uint64(b)
+		}
+		d.pos += size
+		return major, info, arg, nil
+	case info == 31 && major != cborUint && major != cborNegative && major != cborTag:
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, d.errorAt(d.pos-1, "invalid CBOR initial byte 0x%02x", c)
+}
+
+// atBreak consumes the break that ends an indefinite-length item
+func (d *cborDecoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) enter() error {
+	if d.depth++; d.depth > defaultMaxDepth {
+		return d.errorAt(d.pos, "exceeded max depth of %d", defaultMaxDepth)
+	}
+	return nil
+}
+
+func (d *cborDecoder) value() (interface{}, error) {
+	start := d.pos
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return cborInt(big.NewInt(0).SetUint64(arg)), nil
+	case cborNegative:
+		return cborInt(new(big.Int).Not(new(big.Int).SetUint64(arg))), nil
+	case cborBytes:
+		data, err := d.str(major, info, arg)
+		if err != nil || d.opts.KeepBinary {
+			return data, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	case cborText:
+		data, err := d.str(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(data) {
+			return nil, d.errorAt(start, "invalid UTF-8 in text string")
+		}
+		return string(data), nil
+	case cborArray:
+		return d.array(info == 31, arg)
+	case cborMap:
+		return d.object(info == 31, arg)
+	case cborTag:
+		return d.tag(arg, start)
+	}
+
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		// null and undefined
+		return nil, nil
+	case 25, 26, 27:
+		f := math.Float64frombits(arg)
+		if info == 25 {
+			f = float16Value(uint16(arg))
+		} else if info == 26 {
+			f = float64(math.Float32frombits(uint32(arg)))
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, d.errorAt(start, "%v cannot be represented in JSON", f)
+		}
+		return f, nil
+	case 31:
+		return nil, d.errorAt(start, "unexpected CBOR break")
+	case 24:
+		if arg < 32 {
+			return nil, d.errorAt(start, "invalid CBOR simple value encoding")
+		}
+	}
+	return nil, d.errorAt(start, "unsupported CBOR simple value %d", arg)
+}
+
+// cborInt converts an integer to float64 when it fits in 53 bits, to
+// int64 or uint64 when it fits in 64, and to json.Number otherwise
+func cborInt(n *big.Int) interface{} {
+	switch {
+	case n.IsInt64():
+		if v := n.Int64(); v >= -1<<53 && v <= 1<<53 {
+			return float64(v)
+		}
+		return n.Int64()
+	case n.IsUint64():
+		return n.Uint64()
+	}
+	return json.Number(n.String())
+}
+
+// str reads the bytes of a byte or text string. An indefinite-length
+// string is the concatenation of definite-length chunks of the same type.
+func (d *cborDecoder) str(major, info byte, n uint64) ([]byte, error) {
+	if info != 31 {
+		if uint64(len(d.data)-d.pos) < n {
+			return nil, d.errorAt(len(d.data), "unexpected end of CBOR input")
+		}
+		data := d.data[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return data, nil
+	}
+	data := []byte{}
+	for !d.atBreak() {
+		start := d.pos
+		chunkMajor, chunkInfo, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, d.errorAt(start, "invalid chunk in indefinite-length CBOR string")
+		}
+		chunk, err := d.str(major, chunkInfo, n)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+func (d *cborDecoder) array(indefinite bool, n uint64) ([]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	arr := make([]interface{}, 0, min(n, uint64(len(d.data)-d.pos)))
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite && d.atBreak() {
+			break
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+	}
+	return arr, nil
+}
+
+// object decodes a map. Text keys are used as they are and integer keys
+// in decimal; other keys are rejected.
+func (d *cborDecoder) object(indefinite bool, n uint64) (map[string]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	obj := make(map[string]interface{}, min(n, uint64(len(d.data)-d.pos)))
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite && d.atBreak() {
+			break
+		}
+		start := d.pos
+		if start < len(d.data) {
+			if major := d.data[start] >> 5; major != cborUint && major != cborNegative && major != cborText {
+				return nil, d.errorAt(start, "unsupported CBOR map key type %d", major)
+			}
+		}
+		key, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		var k string
+		switch key := key.(type) {
+		case string:
+			k = key
+		case float64:
+			k = strconv.FormatFloat(key, 'f', -1, 64)
+		default:
+			k = fmt.Sprint(key)
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[k] = value
+	}
+	return obj, nil
+}
+
+// tag decodes a tagged item. Dates and bignums are converted and the
+// content of other tags is returned as it is.
+func (d *cborDecoder) tag(num uint64, start int) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	want := -1
+	switch num {
+	case cborTagDateString:
+		want = cborText
+	case cborTagBignum, cborTagNegBignum:
+		want = cborBytes
+	}
+	if want >= 0 && d.pos < len(d.data) && int(d.data[d.pos]>>5) != want {
+		return nil, d.errorAt(start, "invalid content for CBOR tag %d", num)
+	}
+	if num == cborTagBignum || num == cborTagNegBignum {
+		_, info, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.str(cborBytes, info, n)
+		if err != nil {
+			return nil, err
+		}
+		b := new(big.Int).SetBytes(data)
+		if num == cborTagNegBignum {
+			b.Not(b)
+		}
+		return cborInt(b), nil
+	}
+
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	switch num {
+	case cborTagDateString:
+		t, err := time.Parse(time.RFC3339Nano, value.(string))
+		if err != nil {
+			return nil, d.errorAt(start, "invalid CBOR date string %q", value)
+		}
+		if d.opts.KeepTimestamps {
+			return t, nil
+		}
+		return value, nil
+	case cborTagEpochDate:
+		f, ok := value.(float64)
+		if !ok || math.Abs(f) >= 1<<53 {
+			return nil, d.errorAt(start, "invalid CBOR epoch date")
+		}
+		sec := math.Floor(f)
+		t := time.Unix(int64(sec), int64(math.Round((f-sec)*1e9))).UTC()
+		if d.opts.KeepTimestamps {
+			return t, nil
+		}
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,295 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Examples from RFC 8949, Appendix A. want is the JSON LoadCBOR gives,
+// empty when the item has no JSON form and must be rejected. encodes
+// marks the items DumpCBOR writes back to the same bytes; the others
+// use a non-preferred or indefinite-length form, or are integral floats,
+// which DumpCBOR writes as integers.
+var cborAppendixA = []struct {
+	hex     string
+	want    string
+	encodes bool
+}{
+	{"00", `0`, true},
+	{"01", `1`, true},
+	{"0a", `10`, true},
+	{"17", `23`, true},
+	{"1818", `24`, true},
+	{"1819", `25`, true},
+	{"1864", `100`, true},
+	{"1903e8", `1000`, true},
+	{"1a000f4240", `1000000`, true},
+	{"1b000000e8d4a51000", `1000000000000`, true},
+	{"1bffffffffffffffff", `18446744073709551615`, true},
+	{"c249010000000000000000", `18446744073709551616`, true},
+	{"3bffffffffffffffff", `-18446744073709551616`, true},
+	{"c349010000000000000000", `-18446744073709551617`, true},
+	{"20", `-1`, true},
+	{"29", `-10`, true},
+	{"3863", `-100`, true},
+	{"3903e7", `-1000`, true},
+	{"f90000", `0`, false},
+	{"f98000", `-0`, true},
+	{"f93c00", `1`, false},
+	{"fb3ff199999999999a", `1.1`, true},
+	{"f93e00", `1.5`, true},
+	{"f97bff", `65504`, false},
+	{"fa47c35000", `100000`, false},
+	{"fa7f7fffff", `3.4028234663852886e+38`, true},
+	{"fb7e37e43c8800759c", `1e+300`, true},
+	{"f90001", `5.960464477539063e-8`, true},
+	{"f90400", `0.00006103515625`, true},
+	{"f9c400", `-4`, false},
+	{"fbc010666666666666", `-4.1`, true},
+	{"f97c00", ``, false},
+	{"f97e00", ``, false},
+	{"f9fc00", ``, false},
+	{"fa7f800000", ``, false},
+	{"fa7fc00000", ``, false},
+	{"faff800000", ``, false},
+	{"fb7ff0000000000000", ``, false},
+	{"fb7ff8000000000000", ``, false},
+	{"fbfff0000000000000", ``, false},
+	{"f4", `false`, true},
+	{"f5", `true`, true},
+	{"f6", `null`, true},
+	{"f7", `null`, false},
+	{"f0", ``, false},
+	{"f8ff", ``, false},
+	{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`, false},
+	{"c11a514b67b0", `"2013-03-21T20:04:00Z"`, false},
+	{"c1fb41d452d9ec200000", `"2013-03-21T20:04:00.5Z"`, false},
+	{"d74401020304", `"AQIDBA=="`, false},
+	{"d818456449455446", `"ZElFVEY="`, false},
+	{"d82076687474703a2f2f7777772e6578616d706c652e636f6d", `"http://www.example.com"`, false},
+	{"40", `""`, false},
+	{"4401020304", `"AQIDBA=="`, false},
+	{"60", `""`, true},
+	{"6161", `"a"`, true},
+	{"6449455446", `"IETF"`, true},
+	{"62225c", `"\"\\"`, true},
+	{"62c3bc", `"ü"`, true},
+	{"63e6b0b4", `"水"`, true},
+	{"64f0908591", `"𐅑"`, true},
+	{"80", `[]`, true},
+	{"83010203", `[1,2,3]`, true},
+	{"8301820203820405", `[1,[2,3],[4,5]]`, true},
+	{"98190102030405060708090a0b0c0d0e0f101112131415161718181819",
+		`[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23,24,25]`, true},
+	{"a0", `{}`, true},
+	{"a201020304", `{"1":2,"3":4}`, false},
+	{"a26161016162820203", `{"a":1,"b":[2,3]}`, true},
+	{"826161a161626163", `["a",{"b":"c"}]`, true},
+	{"a56161614161626142616361436164614461656145", `{"a":"A","b":"B","c":"C","d":"D","e":"E"}`, true},
+	{"5f42010243030405ff", `"AQIDBAU="`, false},
+	{"7f657374726561646d696e67ff", `"streaming"`, false},
+	{"9fff", `[]`, false},
+	{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`, false},
+	{"9f01820203820405ff", `[1,[2,3],[4,5]]`, false},
+	{"83018202039f0405ff", `[1,[2,3],[4,5]]`, false},
+	{"83019f0203ff820405", `[1,[2,3],[4,5]]`, false},
+	{"9f0102030405060708090a0b0c0d0e0f101112131415161718181819ff",
+		`[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23,24,25]`, false},
+	{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`, false},
+	{"826161bf61626163ff", `["a",{"b":"c"}]`, false},
+	{"bf6346756ef563416d7421ff", `{"Amt":-2,"Fun":true}`, false},
+}
+
+func TestCBORAppendixA(t *testing.T) {
+	for _, tt := range cborAppendixA {
+		in, err := hex.DecodeString(tt.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jv, err := LoadCBOR(in, CBOROptions{})
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: expected error, got %s", tt.hex, dumps(t, jv))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.hex, err)
+			continue
+		}
+		if got := dumps(t, jv); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.hex, tt.want, got)
+		}
+		if !tt.encodes {
+			continue
+		}
+		out, err := jv.DumpCBOR()
+		if err != nil {
+			t.Errorf("%s: %v", tt.hex, err)
+		} else if got := hex.EncodeToString(out); got != tt.hex {
+			t.Errorf("%s: encoded as %s", tt.hex, got)
+		}
+	}
+}
+
+func TestDumpCBOR(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{1.0, "01"},
+		{-4.0, "23"},
+		{float32(0.5), "f93800"},
+		{float32(1.1), "fa3f8ccccd"},
+		{100000.5, "fa47c35040"},
+		{float64(-(1 << 64)), "3bffffffffffffffff"},
+		{float64(1 << 64), "fa5f800000"},
+		{json.Number("1.0"), "01"},
+		{json.Number("-9223372036854775809"), "3b8000000000000000"},
+		{json.Number("1e-1"), "fb3fb999999999999a"},
+		{big.NewInt(-5), "24"},
+		{uint8(200), "18c8"},
+		{[]byte{1, 2}, "420102"},
+		{"bad\xff", "66626164efbfbd"},
+		{time.Unix(1363896240, 0), "c11a514b67b0"},
+		{time.Unix(1363896240, 5e8), "c1fb41d452d9ec200000"},
+		{map[string]interface{}{"bb": 1, "c": 2, "a": 3, "": 4}, "a46004616103616302626262" + "01"},
+		{New([]interface{}{nil}), "81f6"},
+		{struct {
+			A []int `json:"a"`
+		}{[]int{1}}, "a161618101"},
+	}
+	for _, tt := range tests {
+		out, err := New(tt.value).DumpCBOR()
+		if err != nil {
+			t.Errorf("%v: %v", tt.value, err)
+			continue
+		}
+		if got := hex.EncodeToString(out); got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.value, tt.want, got)
+		}
+	}
+
+	for _, bad := range []interface{}{math.NaN(), math.Inf(1), []interface{}{float32(math.Inf(-1))}} {
+		if _, err := New(bad).DumpCBOR(); err == nil {
+			t.Errorf("Expected error for %v", bad)
+		}
+	}
+}
+
+func TestLoadCBOR(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"1b0020000000000001", `9007199254740993`},
+		{"3b0020000000000000", `-9007199254740993`},
+		{"c240", `0`},
+		{"c34100", `-1`},
+		{"c25f4101420000ff", `65536`},
+		{"a1190100f6", `{"256":null}`},
+		{"a2616101616102", `{"a":2}`},
+		{"d9d9f7a0", `{}`},
+		{"c1f93e00", `"1970-01-01T00:00:01.5Z"`},
+		{"c13a7fffffff", `"1901-12-13T20:45:52Z"`},
+		{"c07819323032342d30312d30325430333a30343a30352b30313a3030", `"2024-01-02T03:04:05+01:00"`},
+	}
+	for _, tt := range tests {
+		in, _ := hex.DecodeString(tt.in)
+		jv, err := LoadCBOR(in, CBOROptions{})
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got := dumps(t, jv); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.in, tt.want, got)
+		}
+	}
+
+	in, _ := hex.DecodeString("a26162420102617401")
+	jv, err := LoadCBOR(in, CBOROptions{KeepBinary: true, KeepTimestamps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := jv.Get("b").Raw().([]byte); !ok || !bytes.Equal(b, []byte{1, 2}) {
+		t.Errorf("Expected []byte, got %#v", jv.Get("b").Raw())
+	}
+	jv.Set("t", time.Unix(1, 0))
+	out, err := jv.DumpCBOR()
+	if err != nil || hex.EncodeToString(out) != "a261624201026174c101" {
+		t.Errorf("Got %x, %v", out, err)
+	}
+	back, err := LoadCBOR(out, CBOROptions{KeepTimestamps: true})
+	if ts, ok := back.Get("t").Raw().(time.Time); err != nil || !ok || !ts.Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected time.Time, got %#v, %v", back.Get("t").Raw(), err)
+	}
+}
+
+func TestLoadCBORErrors(t *testing.T) {
+	tests := []struct {
+		in, msg string
+	}{
+		{"", "unexpected end"},
+		{"1c", "invalid CBOR initial byte 0x1c at offset 0"},
+		{"1f", "invalid CBOR initial byte"},
+		{"df", "invalid CBOR initial byte"},
+		{"19", "unexpected end"},
+		{"8201", "unexpected end"},
+		{"9f01", "unexpected end"},
+		{"6361", "unexpected end"},
+		{"ff", "unexpected CBOR break"},
+		{"0101", "trailing data after CBOR item at offset 1"},
+		{"f818", "invalid CBOR simple value"},
+		{"61ff", "invalid UTF-8"},
+		{"5f6161ff", "invalid chunk"},
+		{"5f5f4101ffff", "invalid chunk"},
+		{"a18001", "map key type 4"},
+		{"a1f601", "map key type 7"},
+		{"c001", "invalid content for CBOR tag 0"},
+		{"c06161", "invalid CBOR date string"},
+		{"c201", "invalid content for CBOR tag 2"},
+		{"c1f5", "invalid CBOR epoch date"},
+		{"c1f97c00", "cannot be represented"},
+	}
+	for _, tt := range tests {
+		in, _ := hex.DecodeString(tt.in)
+		_, err := LoadCBOR(in, CBOROptions{})
+		if err == nil || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: expected %q, got %v", tt.in, tt.msg, err)
+		}
+	}
+
+	for _, deep := range [][]byte{bytes.Repeat([]byte{0x81}, defaultMaxDepth+1), bytes.Repeat([]byte{0xd8, 0x20}, defaultMaxDepth+1)} {
+		if _, err := LoadCBOR(deep, CBOROptions{}); err == nil || !strings.Contains(err.Error(), "max depth") {
+			t.Errorf("Expected depth error, got %v", err)
+		}
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	docs := []string{
+		`null`,
+		`{"name": "sensor", "readings": [1, -2, 0.5, 1.1, 65504, 1e300, -1e-300, 5000000000], "ok": true,
+			"nested": {"a": [[], {}, [{"b": null}]], "s": "é ü 😀"}}`,
+		`[` + strings.Repeat(`"x",`, 30) + `{"` + strings.Repeat("k", 300) + `": "` + strings.Repeat("v", 70000) + `"}]`,
+	}
+	for _, doc := range docs {
+		jv := mustLoads(t, doc)
+		out, err := jv.DumpCBOR()
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := LoadCBOR(out, CBOROptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !back.Equal(jv, EqualOptions{}) {
+			t.Errorf("Round trip of %.100s gave %.100s", doc, dumps(t, back))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClonePreservesTypes(t *testing.T) {
+	type point struct {
+		X, Y   int
+		Labels []string
+		hidden *int
+	}
+	hidden := 5
+	inner := New(map[string]interface{}{"k": "v"})
+	original := map[string]interface{}{
+		"int":    1,
+		"int64":  int64(2),
+		"uint8":  uint8(3),
+		"number": json.Number("4.5"),
+		"nil":    nil,
+		"strs":   []string{"a", "b"},
+		"typed":  map[string]int{"x": 1},
+		"array":  [2]int{1, 2},
+		"point":  &point{X: 1, Labels: []string{"p"}, hidden: &hidden},
+		"inner":  inner,
+		"nested": []interface{}{map[string]interface{}{"deep": []interface{}{true}}},
+	}
+	jv := New(original)
+	cloned := jv.Clone()
+
+	if !reflect.DeepEqual(cloned.Raw(), original) {
+		t.Fatalf("Clone changed the value:\n%#v\n%#v", cloned.Raw(), original)
+	}
+	if _, ok := cloned.Get("int").Raw().(int); !ok {
+		t.Errorf("Expected int to stay an int, got %T", cloned.Get("int").Raw())
+	}
+
+	// Mutating the copy must not touch the original
+	c := cloned.Raw().(map[string]interface{})
+	c["strs"].([]string)[0] = "changed"
+	c["typed"].(map[string]int)["x"] = 9
+	c["point"].(*point).Labels[0] = "changed"
+	c["inner"].(*JSONValue).Set("k", "changed")
+	c["nested"].([]interface{})[0].(map[string]interface{})["deep"].([]interface{})[0] = false
+	if s, _ := jv.Dumps(); strings.Contains(s, "changed") || strings.Contains(s, "false") || strings.Contains(s, "9") {
+		t.Errorf("Clone shares data with the original: %s", s)
+	}
+	if c["point"].(*point).hidden != &hidden {
+		t.Error("Expected unexported fields to be copied shallowly")
+	}
+}
+
+func TestCloneUnmarshalable(t *testing.T) {
+	ch := make(chan int)
+	cloned := New(map[string]interface{}{"ch": ch}).Clone()
+	if cloned.Get("ch").Raw() != ch {
+		t.Error("Expected values that cannot be marshaled to survive Clone")
+	}
+	if !New(nil).Clone().IsNull() {
+		t.Error("Expected null clone")
+	}
+}
+
+func benchmarkCloneDoc(b *testing.B) *JSONValue {
+	b.Helper()
+	var sb strings.Builder
+	sb.WriteString(`{"items": [`)
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(`{"id": 1, "name": "item", "tags": ["a", "b", "c"], "price": 9.99, "active": true}`)
+	}
+	sb.WriteString(`]}`)
+	jv, err := Loads(sb.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	return jv
+}
+
+func BenchmarkClone(b *testing.B) {
+	jv := benchmarkCloneDoc(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		jv.Clone()
+	}
+}
+
+// BenchmarkCloneMarshal measures the previous marshal and unmarshal approach
+func BenchmarkCloneMarshal(b *testing.B) {
+	jv := benchmarkCloneDoc(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, _ := json.Marshal(jv.data)
+		var cloned interface{}
+		json.Unmarshal(data, &cloned)
+	}
+}
@@ -0,0 +1,142 @@
+package easyjson
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Theme holds the ANSI escape sequences used to color each kind of token.
+// An empty field leaves that kind of token uncolored.
+type Theme struct {
+	Key         string
+	String      string
+	Number      string
+	Bool        string
+	Null        string
+	Punctuation string // braces, brackets, colons and commas
+}
+
+// ANSI escape sequences for building themes
+const (
+	ColorReset   = "\x1b[0m"
+	ColorBold    = "\x1b[1m"
+	ColorFaint   = "\x1b[2m"
+	ColorRed     = "\x1b[31m"
+	ColorGreen   = "\x1b[32m"
+	ColorYellow  = "\x1b[33m"
+	ColorBlue    = "\x1b[34m"
+	ColorMagenta = "\x1b[35m"
+	ColorCyan    = "\x1b[36m"
+)
+
+// DefaultTheme is the theme used by %+v and a reasonable choice for DumpsColor
+var DefaultTheme = Theme{
+	Key:    ColorBold + ColorBlue,
+	String: ColorGreen,
+	Number: ColorCyan,
+	Bool:   ColorYellow,
+	Null:   ColorMagenta,
+}
+
+// NoColor is a theme that colors nothing
+var NoColor = Theme{}
+
+// DumpsColor converts the JSONValue to an indented string with tokens
+// colored by theme, for printing to a terminal
+func (jv *JSONValue) DumpsColor(theme Theme) (string, error) {
+	var buf bytes.Buffer
+	enc := &Encoder{w: &buf, opts: encodeOptions{indent: "  ", sortKeys: true, floatPrec: -1, theme: &theme}}
+	if err := enc.Encode(jv); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+// SetTheme colors the encoder's output with theme when the underlying
+// writer is a terminal. Colors are left out for files, pipes and other
+// writers, and when the NO_COLOR environment variable is set.
+func (e *Encoder) SetTheme(theme Theme) {
+	if !isTerminal(e.w) || os.Getenv("NO_COLOR") != "" {
+		e.opts.theme = nil
+		return
+	}
+	e.opts.theme = &theme
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// stdoutIsTerminal reports whether %+v output may be colored. It is a
+// variable so tests can stand in for a terminal.
+var stdoutIsTerminal = func() bool { return isTerminal(os.Stdout) }
+
+// Format implements fmt.Formatter. The %v and %s verbs print the compact
+// form from Dumps, %q prints it quoted, and %+v prints indented output.
+// Like SetTheme, %+v colors it with DefaultTheme only when stdout is a
+// terminal and NO_COLOR is unset.
+func (jv *JSONValue) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		theme := DefaultTheme
+		if !stdoutIsTerminal() || os.Getenv("NO_COLOR") != "" {
+			theme = NoColor
+		}
+		out, err := jv.DumpsColor(theme)
+		if err != nil {
+			out = jv.String()
+		}
+		io.WriteString(f, out)
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, "easyjson.New(%#v)", jv.data)
+	case verb == 'v' || verb == 's':
+		io.WriteString(f, jv.String())
+	case verb == 'q':
+		io.WriteString(f, strconv.Quote(jv.String()))
+	default:
+		fmt.Fprintf(f, "%%!%c(*easyjson.JSONValue=%s)", verb, jv.String())
+	}
+}
+
+// color starts a token in the given theme color and returns the
+// sequence that ends it
+func (s *encodeState) color(color string) string {
+	if color == "" {
+		return ""
+	}
+	s.buf = append(s.buf, color...)
+	return ColorReset
+}
+
+// punct writes a punctuation character in the theme's punctuation color
+func (s *encodeState) punct(c byte) {
+	if s.opts.theme == nil {
+		s.buf = append(s.buf, c)
+		return
+	}
+	end := s.color(s.opts.theme.Punctuation)
+	s.buf = append(s.buf, c)
+	s.buf = append(s.buf, end...)
+}
+
+// scalarColor picks the theme color for a scalar value
+func (t *Theme) scalarColor(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return t.Null
+	case bool:
+		return t.Bool
+	case string:
+		return t.String
+	}
+	return t.Number
+}
@@ -0,0 +1,103 @@
+package easyjson
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestDumpsColor(t *testing.T) {
+	jv, _ := Loads(`{"name": "Ann", "age": 30, "admin": true, "boss": null, "tags": ["a"], "empty": {}}`)
+
+	out, err := jv.DumpsColor(DefaultTheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		DefaultTheme.Key + `"name"` + ColorReset,
+		DefaultTheme.String + `"Ann"` + ColorReset,
+		DefaultTheme.Number + "30" + ColorReset,
+		DefaultTheme.Bool + "true" + ColorReset,
+		DefaultTheme.Null + "null" + ColorReset,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in %q", want, out)
+		}
+	}
+
+	plain, _ := jv.DumpsIndent("  ")
+	if got := ansiEscape.ReplaceAllString(out, ""); got != plain {
+		t.Errorf("Expected colors over\n%s\ngot\n%s", plain, got)
+	}
+	if got, _ := jv.DumpsColor(NoColor); got != plain {
+		t.Errorf("Expected NoColor to match DumpsIndent, got\n%s", got)
+	}
+
+	punct, _ := New([]interface{}{}).DumpsColor(Theme{Punctuation: ColorFaint})
+	if punct != ColorFaint+"["+ColorReset+ColorFaint+"]"+ColorReset {
+		t.Errorf("Unexpected punctuation colors %q", punct)
+	}
+}
+
+func TestEncoderThemeNotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetTheme(DefaultTheme)
+	enc.Encode(New(map[string]interface{}{"a": 1}))
+	if buf.String() != "{\"a\":1}\n" {
+		t.Errorf("Expected no colors for a buffer, got %q", buf.String())
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("Expected a regular file not to be a terminal")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	jv, _ := Loads(`{"a": [1, "x"]}`)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", `{"a":[1,"x"]}`},
+		{"%s", `{"a":[1,"x"]}`},
+		{"%q", `"{\"a\":[1,\"x\"]}"`},
+		{"%#v", `easyjson.New(map[string]interface {}{"a":[]interface {}{1, "x"}})`},
+		{"%d", `%!d(*easyjson.JSONValue={"a":[1,"x"]})`},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, jv); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.format, tt.want, got)
+		}
+	}
+
+	defer func(saved func() bool) { stdoutIsTerminal = saved }(stdoutIsTerminal)
+	want, _ := jv.DumpsIndent("  ")
+
+	stdoutIsTerminal = func() bool { return false }
+	t.Setenv("NO_COLOR", "")
+	if got := fmt.Sprintf("%+v", jv); got != want {
+		t.Errorf("Expected no colors when stdout is not a terminal, got %q", got)
+	}
+
+	stdoutIsTerminal = func() bool { return true }
+	colored := fmt.Sprintf("%+v", jv)
+	if !strings.Contains(colored, DefaultTheme.Key+`"a"`) || !strings.Contains(colored, "\n") {
+		t.Errorf("Expected indented colored output, got %q", colored)
+	}
+	t.Setenv("NO_COLOR", "1")
+	if got := fmt.Sprintf("%+v", jv); got != want {
+		t.Errorf("Expected NO_COLOR to disable colors, got %q", got)
+	}
+}
@@ -0,0 +1,481 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// syntaxTree is the concrete syntax of a document parsed with
+// WithPreserveFormat
+type syntaxTree struct {
+	src    []byte
+	root   *cstNode
+	indent string // one level of indentation, detected from the source
+}
+
+// cstNode records where a value appears in the source. For containers,
+// value is the map or slice the parser produced, which identifies it
+// among edited siblings.
+type cstNode struct {
+	start, end    int
+	value         interface{}
+	members       []cstMember
+	closeStart    int // start of the trivia before the closing delimiter
+	trailingComma bool
+}
+
+// cstMember is one object member or array element. The source between
+// lead and start holds the whitespace and comments in front of it;
+// start..keyEnd is the key and keyEnd..node.start the colon (both empty
+// for array elements); node.end..trail is the trivia before the comma.
+type cstMember struct {
+	key    string
+	lead   int
+	start  int
+	keyEnd int
+	node   *cstNode
+	trail  int
+}
+
+func newSyntaxTree(src []byte, root *cstNode) *syntaxTree {
+	t := &syntaxTree{src: append([]byte(nil), src...), root: root, indent: "  "}
+	if len(root.members) > 0 {
+		m := root.members[0]
+		if indent := leadIndent(string(src[m.lead:m.start])); indent != "" {
+			t.indent = indent
+		}
+	}
+	return t
+}
+
+func (p *parser) newNode() *cstNode {
+	if !p.opts.PreserveFormat {
+		return nil
+	}
+	return &cstNode{start: p.pos}
+}
+
+func (n *cstNode) addMember(m cstMember, trail int) {
+	if n != nil {
+		m.trail = trail
+		n.members = append(n.members, m)
+	}
+}
+
+// closeNode consumes the closing delimiter of a container
+func (p *parser) closeNode(n *cstNode, value interface{}, closeStart int, trailingComma bool) {
+	p.pos++
+	if n != nil {
+		n.value = value
+		n.closeStart = closeStart
+		n.trailingComma = trailingComma
+		n.end = p.pos
+		p.node = n
+	}
+}
+
+// DumpsPreserved serializes a value parsed with WithPreserveFormat. Regions
+// that were not edited are copied from the source byte for byte, keeping
+// comments, whitespace, key order and number formatting; edited values
+// are re-encoded in place and new members follow the surrounding layout.
+// Values without a syntax tree are serialized like Dumps.
+func (jv *JSONValue) DumpsPreserved() (string, error) {
+	if jv.tree == nil {
+		return jv.Dumps()
+	}
+	t := jv.tree
+	w := &cstWriter{tree: t}
+	w.buf.Write(t.src[:t.root.start])
+	if err := w.render(t.root, jv.data, ""); err != nil {
+		return "", err
+	}
+	w.buf.Write(t.src[t.root.end:])
+	return w.buf.String(), nil
+}
+
+type cstWriter struct {
+	tree *syntaxTree
+	buf  bytes.Buffer
+}
+
+func (w *cstWriter) text(start, end int) string {
+	return string(w.tree.src[start:end])
+}
+
+// render writes value, reusing the source of n wherever they agree
+func (w *cstWriter) render(n *cstNode, value interface{}, indent string) error {
+	src := w.tree.src
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if src[n.start] == '{' {
+			return w.renderObject(n, v, indent)
+		}
+	case []interface{}:
+		if src[n.start] == '[' {
+			return w.renderArray(n, v, indent)
+		}
+	default:
+		if !isContainer(n.value) && sameScalar(n.value, v) {
+			w.buf.Write(src[n.start:n.end])
+			return nil
+		}
+	}
+	return w.encode(value, indent, bytes.IndexByte(src[n.start:n.end], '\n') >= 0)
+}
+
+// cstItem is one member of a container as it will be written: an
+// original member (index >= 0) or one added by an edit
+type cstItem struct {
+	index int
+	key   string
+	value interface{}
+}
+
+func (w *cstWriter) renderObject(n *cstNode, obj map[string]interface{}, indent string) error {
+	items := make([]cstItem, 0, len(obj))
+	emitted := make(map[string]bool, len(obj))
+	for i, m := range n.members {
+		value, ok := obj[m.key]
+		if !ok || emitted[m.key] {
+			continue
+		}
+		emitted[m.key] = true
+		items = append(items, cstItem{index: i, key: m.key, value: value})
+	}
+
+	keys := make([]string, 0, len(obj)-len(emitted))
+	for k := range obj {
+		if !emitted[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		items = append(items, cstItem{index: -1, key: k, value: obj[k]})
+	}
+	return w.renderContainer(n, items, indent, '{', '}')
+}
+
+func (w *cstWriter) renderArray(n *cstNode, arr []interface{}, indent string) error {
+	items := make([]cstItem, len(arr))
+	for i, index := range alignElements(n.members, arr) {
+		items[i] = cstItem{index: index, value: arr[i]}
+	}
+	return w.renderContainer(n, items, indent, '[', ']')
+}
+
+// renderContainer writes the members of an object or array. Comments on
+// the same line after a comma belong to the member before it and move
+// with that member.
+func (w *cstWriter) renderContainer(n *cstNode, items []cstItem, indent string, open, closing byte) error {
+	// Split the trivia after each member into the part that stays on its
+	// line and the lead of whatever follows
+	leads := make([]string, len(n.members))
+	after := make([]string, len(n.members))
+	closeText := w.text(n.closeStart, n.end-1)
+	for i, m := range n.members {
+		leads[i] = w.text(m.lead, m.start)
+		if i > 0 {
+			after[i-1], leads[i] = splitLine(leads[i])
+		}
+	}
+	if len(n.members) > 0 {
+		after[len(n.members)-1], closeText = splitLine(closeText)
+	}
+
+	w.buf.WriteByte(open)
+	for k, item := range items {
+		var lead string
+		if item.index >= 0 {
+			lead = w.movedLead(leads, item.index, k)
+		} else {
+			lead = w.newLead(n, leads, closeText, indent, k)
+		}
+		w.buf.WriteString(lead)
+
+		if item.index >= 0 {
+			m := &n.members[item.index]
+			w.buf.Write(w.tree.src[m.start:m.node.start])
+			if err := w.render(m.node, item.value, memberIndent(lead, indent)); err != nil {
+				return err
+			}
+			w.buf.Write(w.tree.src[m.node.end:m.trail])
+		} else {
+			if open == '{' {
+				key, _ := json.Marshal(item.key)
+				w.buf.Write(key)
+				w.buf.WriteString(w.colon(n))
+			}
+			if err := w.encode(item.value, memberIndent(lead, indent), strings.Contains(lead, "\n")); err != nil {
+				return err
+			}
+		}
+
+		if k < len(items)-1 || n.trailingComma {
+			w.buf.WriteByte(',')
+		}
+		if item.index >= 0 {
+			w.buf.WriteString(after[item.index])
+		}
+	}
+
+	if len(items) == 0 && len(n.members) > 0 && isSpace(closeText) {
+		closeText = ""
+	}
+	w.buf.WriteString(closeText)
+	w.buf.WriteByte(closing)
+	return nil
+}
+
+// splitLine splits trivia at its first line break when the part before
+// it holds a comment
+func splitLine(trivia string) (sameLine, rest string) {
+	i := strings.IndexByte(trivia, '\n')
+	if i < 0 || isSpace(trivia[:i]) {
+		return "", trivia
+	}
+	return trivia[:i], trivia[i:]
+}
+
+// movedLead returns the lead of member i written at output position k. A
+// member that moved to or from the front takes the plain whitespace of
+// its new position, so removing 1 from "[1, 2]" gives "[2]".
+func (w *cstWriter) movedLead(leads []string, i, k int) string {
+	lead := leads[i]
+	if (k == 0) != (i == 0) && isSpace(lead) {
+		ref := 0
+		if k > 0 && len(leads) > 1 {
+			ref = 1
+		}
+		if isSpace(leads[ref]) {
+			return leads[ref]
+		}
+	}
+	return lead
+}
+
+// newLead chooses the whitespace in front of a member added by an edit:
+// multi-line containers get a new line at the indentation of their last
+// member, compact ones the spacing of their other members
+func (w *cstWriter) newLead(n *cstNode, leads []string, closeText, indent string, k int) string {
+	for i := len(leads) - 1; i >= 0; i-- {
+		if strings.Contains(leads[i], "\n") {
+			return "\n" + leadIndent(leads[i])
+		}
+	}
+	if len(leads) == 0 && strings.Contains(closeText, "\n") {
+		return "\n" + indent + w.tree.indent
+	}
+	if k == 0 {
+		return ""
+	}
+	if last := len(leads) - 1; last > 0 && isSpace(leads[last]) {
+		return leads[last]
+	}
+	return " "
+}
+
+// colon returns the separator between a new key and its value, copied
+// from the last existing member when it holds only whitespace
+func (w *cstWriter) colon(n *cstNode) string {
+	if len(n.members) > 0 {
+		m := n.members[len(n.members)-1]
+		if sep := w.text(m.keyEnd, m.node.start); strings.TrimSpace(sep) == ":" {
+			return sep
+		}
+	}
+	return ": "
+}
+
+// encode writes a value that has no source representation
+func (w *cstWriter) encode(value interface{}, indent string, multiline bool) error {
+	var data []byte
+	var err error
+	if multiline {
+		data, err = json.MarshalIndent(value, indent, w.tree.indent)
+	} else {
+		data, err = json.Marshal(value)
+	}
+	if err != nil {
+		return err
+	}
+	w.buf.Write(data)
+	return nil
+}
+
+// alignElements pairs the current elements of an array with the original
+// members they came from. Unchanged scalars and containers that are still
+// the same map or slice are matched along their longest common
+// subsequence; the leftovers between two matches are paired up in order
+// when they are containers of the same kind, so edits inside them keep
+// their layout. Elements without an original member map to -1.
+func alignElements(members []cstMember, arr []interface{}) []int {
+	matched := make([]int, len(arr))
+	for i := range matched {
+		matched[i] = -1
+	}
+	n, m := len(members), len(arr)
+	if n == 0 || m == 0 {
+		return matched
+	}
+
+	same := func(i, j int) bool {
+		if parsed := members[i].node.value; isContainer(parsed) {
+			return sameContainer(parsed, arr[j])
+		}
+		return sameScalar(members[i].node.value, arr[j])
+	}
+
+	if n*m > 1<<20 {
+		for j := 0; j < m && j < n; j++ {
+			matched[j] = j
+		}
+		return matched
+	}
+
+	// lcs[i][j] is the LCS length of members[i:] and arr[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if same(i, j) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	gapI, gapJ := 0, 0
+	pairGap := func(endI, endJ int) {
+		for gi, gj := gapI, gapJ; gi < endI && gj < endJ; gj++ {
+			if sameKind(members[gi].node.value, arr[gj]) {
+				matched[gj] = gi
+				gi++
+			}
+		}
+	}
+	for i < n && j < m {
+		switch {
+		case same(i, j):
+			pairGap(i, j)
+			matched[j] = i
+			i++
+			j++
+			gapI, gapJ = i, j
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	pairGap(n, m)
+	return matched
+}
+
+func isContainer(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+func sameKind(a, b interface{}) bool {
+	switch a.(type) {
+	case map[string]interface{}:
+		_, ok := b.(map[string]interface{})
+		return ok
+	case []interface{}:
+		_, ok := b.([]interface{})
+		return ok
+	}
+	return false
+}
+
+// sameContainer reports whether a and b are the same map or slice
+func sameContainer(a, b interface{}) bool {
+	if !sameKind(a, b) {
+		return false
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+}
+
+// sameScalar compares a parsed scalar with a possibly edited value,
+// treating numbers of any Go type as equal when their values are
+func sameScalar(parsed, value interface{}) bool {
+	if f, ok := parsed.(float64); ok {
+		g, ok := toFloat64(value)
+		return ok && (f == g || (math.IsNaN(f) && math.IsNaN(g)))
+	}
+	return parsed == value
+}
+
+// toFloat64 converts any Go numeric type or json.Number to float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// leadIndent returns the indentation at the end of a member's leading
+// trivia, or "" when the member does not start a line
+func leadIndent(lead string) string {
+	i := strings.LastIndexByte(lead, '\n')
+	if i < 0 {
+		return ""
+	}
+	rest := lead[i+1:]
+	end := 0
+	for end < len(rest) && (rest[end] == ' ' || rest[end] == '\t') {
+		end++
+	}
+	return rest[:end]
+}
+
+// memberIndent is the indentation of a member's line
+func memberIndent(lead, parent string) string {
+	if strings.Contains(lead, "\n") {
+		return leadIndent(lead)
+	}
+	return parent
+}
+
+func isSpace(s string) bool {
+	return strings.Trim(s, " \t\r\n") == ""
+}
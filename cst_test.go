@@ -0,0 +1,216 @@
+package easyjson
+
+import (
+	"testing"
+)
+
+const preservedConfig = `// service configuration
+{
+  "name": "api",   // display name
+  "port": 8080,
+  "ratio": 1.50,
+  "tags": [
+    "a",
+    "b" // last tag
+  ],
+  /* connection settings */
+  "db": {
+    "host": "localhost",
+    "pool": 10
+  },
+  "plugins": [],
+}
+`
+
+func loadPreserved(t *testing.T, input string) *JSONValue {
+	t.Helper()
+	jv, err := Loads(input, WithSyntax(SyntaxJSONC), WithPreserveFormat())
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	return jv
+}
+
+func dumpPreserved(t *testing.T, jv *JSONValue) string {
+	t.Helper()
+	out, err := jv.DumpsPreserved()
+	if err != nil {
+		t.Fatalf("DumpsPreserved failed: %v", err)
+	}
+	return out
+}
+
+func TestPreserveFormatUnchanged(t *testing.T) {
+	inputs := []string{
+		preservedConfig,
+		`[1, 2.50, "x", true, null, {}, []]`,
+		"  \"scalar\"  \n",
+		"{\n\t\"tabs\": [ 1 ,2 ],\n}",
+	}
+	for _, input := range inputs {
+		if got := dumpPreserved(t, loadPreserved(t, input)); got != input {
+			t.Errorf("Round trip changed input:\n%s\ngot:\n%s", input, got)
+		}
+	}
+}
+
+func TestPreserveFormatEdits(t *testing.T) {
+	jv := loadPreserved(t, preservedConfig)
+
+	if err := jv.Set("port", 9090); err != nil {
+		t.Fatal(err)
+	}
+	if err := jv.SetPath("db.host", "db.internal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := jv.SetPath("db.timeout", 30); err != nil {
+		t.Fatal(err)
+	}
+	if err := jv.Delete("ratio"); err != nil {
+		t.Fatal(err)
+	}
+	if err := jv.Get("tags").Append("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := jv.Get("plugins").Append("auth"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `// service configuration
+{
+  "name": "api",   // display name
+  "port": 9090,
+  "tags": [
+    "a",
+    "b", // last tag
+    "c"
+  ],
+  /* connection settings */
+  "db": {
+    "host": "db.internal",
+    "pool": 10,
+    "timeout": 30
+  },
+  "plugins": ["auth"],
+}
+`
+	if got := dumpPreserved(t, jv); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPreserveFormatArrays(t *testing.T) {
+	tests := []struct {
+		input string
+		edit  func(jv *JSONValue) error
+		want  string
+	}{
+		{
+			`[1, 2, 3]`,
+			func(jv *JSONValue) error { return jv.Delete(0) },
+			`[2, 3]`,
+		},
+		{
+			`[1, 2, 3]`,
+			func(jv *JSONValue) error { return jv.Delete(1) },
+			`[1, 3]`,
+		},
+		{
+			"[\n  {\"id\": 1}, // first\n  {\"id\": 2} // second\n]",
+			func(jv *JSONValue) error { return jv.Delete(0) },
+			"[\n  {\"id\": 2} // second\n]",
+		},
+		{
+			"[\n  {\"id\": 1},\n  {\"id\": 2}\n]",
+			func(jv *JSONValue) error { return jv.SetPath("1.id", 20) },
+			"[\n  {\"id\": 1},\n  {\"id\": 20}\n]",
+		},
+		{
+			"{\n  \"list\": [\n    1\n  ]\n}",
+			func(jv *JSONValue) error { return jv.Get("list").Append(map[string]interface{}{"k": "v"}) },
+			"{\n  \"list\": [\n    1,\n    {\n      \"k\": \"v\"\n    }\n  ]\n}",
+		},
+		{
+			`[1,2]`,
+			func(jv *JSONValue) error { return jv.Append(3) },
+			`[1,2,3]`,
+		},
+		{
+			`{"a": 1, "b": 2}`,
+			func(jv *JSONValue) error { return jv.Delete("a") },
+			`{"b": 2}`,
+		},
+		{
+			"{\n  \"a\": 1\n}",
+			func(jv *JSONValue) error { return jv.Delete("a") },
+			`{}`,
+		},
+	}
+
+	for _, test := range tests {
+		jv := loadPreserved(t, test.input)
+		if err := test.edit(jv); err != nil {
+			t.Fatalf("Edit of %q failed: %v", test.input, err)
+		}
+		if got := dumpPreserved(t, jv); got != test.want {
+			t.Errorf("Edit of %q:\ngot  %q\nwant %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestDumpsPreservedWithoutTree(t *testing.T) {
+	jv := New(map[string]interface{}{"a": 1})
+	got, err := jv.DumpsPreserved()
+	if err != nil || got != `{"a":1}` {
+		t.Errorf("Expected Dumps output, got %q (%v)", got, err)
+	}
+}
+
+func TestGetWritesBackArrays(t *testing.T) {
+	jv, _ := Loads(`{"items": [1, 2, 3]}`)
+
+	jv.Get("items").Append(4)
+	jv.Path("items").Delete(0)
+	jv.Q("items").Extend([]interface{}{5, 6})
+
+	want := `{"items":[2,3,4,5,6]}`
+	if got, _ := jv.Dumps(); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestStaleHandlesDoNotWriteBack(t *testing.T) {
+	arr, _ := Loads(`[[1], [2], [3]]`)
+	h := arr.Get(1)
+	arr.Delete(0)
+	h.Append(99)
+	if got, _ := arr.Dumps(); got != `[[2],[3]]` {
+		t.Errorf("After deleting before the handle: got %s", got)
+	}
+
+	root, _ := Loads(`{"list": [1, 2]}`)
+	h = root.Get("list")
+	root.Delete("list")
+	h.Append(3)
+	if got, _ := root.Dumps(); got != `{}` {
+		t.Errorf("After deleting the key: got %s", got)
+	}
+
+	root, _ = Loads(`{"list": [1, 2]}`)
+	h = root.Get("list")
+	root.Set("list", []interface{}{"x"})
+	h.Append(3)
+	if got, _ := root.Dumps(); got != `{"list":["x"]}` {
+		t.Errorf("After replacing the key: got %s", got)
+	}
+
+	// a handle still in place keeps writing back after earlier appends
+	root, _ = Loads(`{"list": []}`)
+	h = root.Get("list")
+	for i := 0; i < 5; i++ {
+		h.Append(i)
+	}
+	if got, _ := root.Dumps(); got != `{"list":[0,1,2,3,4]}` {
+		t.Errorf("Repeated appends: got %s", got)
+	}
+}
@@ -0,0 +1,223 @@
+package easyjson
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CSVOptions configures ToCSV and FromCSV
+type CSVOptions struct {
+	// Comma is the field delimiter, ',' when zero. Use '\t' for TSV.
+	Comma rune
+
+	// Columns lists the column paths in order. ToCSV derives them from
+	// the sorted union of every row's flattened keys when empty, and
+	// FromCSV reads them from the header row.
+	Columns []string
+
+	// NoHeader leaves out the header row when writing, and treats the
+	// first row as data when reading, which then requires Columns
+	NoHeader bool
+
+	// Flatten controls how nested values map to column paths such as
+	// "user.address.city"
+	Flatten FlattenOptions
+
+	// InferTypes makes FromCSV read cells holding JSON literals, numbers,
+	// objects or arrays as those values instead of strings
+	InferTypes bool
+}
+
+func (o *CSVOptions) comma() rune {
+	if o.Comma == 0 {
+		return ','
+	}
+	return o.Comma
+}
+
+// ToCSV writes an array of objects to w as CSV, one row per object.
+// Nested values are flattened into one column per leaf, strings are
+// written as they are and other values as JSON. Null and missing values
+// are empty cells. With explicit Columns, a path naming an object or
+// array writes it as JSON in a single cell.
+func (jv *JSONValue) ToCSV(w io.Writer, opts CSVOptions) error {
+	arr, ok := jv.data.([]interface{})
+	if !ok {
+		return fmt.Errorf("cannot convert non-array type to CSV")
+	}
+	flatOpts := opts.Flatten.withDefaults()
+
+	rows := make([]map[string]*JSONValue, len(arr))
+	for i, item := range arr {
+		obj, ok := unwrap(item).(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot convert row %d of non-object type to CSV", i)
+		}
+		if len(opts.Columns) == 0 && len(obj) > 0 {
+			rows[i] = (&JSONValue{data: obj}).FlattenMap(flatOpts)
+		}
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			for path := range row {
+				if !seen[path] {
+					seen[path] = true
+					columns = append(columns, path)
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.comma()
+	if !opts.NoHeader {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(columns))
+	for i, item := range arr {
+		for j, path := range columns {
+			var value interface{}
+			if rows[i] != nil {
+				if v, ok := rows[i][path]; ok {
+					value = v.data
+				}
+			} else if len(opts.Columns) > 0 {
+				var err error
+				if value, err = flatLookup(item, path, &flatOpts); err != nil {
+					return err
+				}
+			}
+			cell, err := csvCell(value)
+			if err != nil {
+				return fmt.Errorf("row %d, column %q: %w", i, path, err)
+			}
+			record[j] = cell
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// flatLookup returns the value at a flattened path below value, or nil
+// when the path is missing
+func flatLookup(value interface{}, path string, opts *FlattenOptions) (interface{}, error) {
+	segments, err := opts.parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segments {
+		switch v := unwrap(value).(type) {
+		case map[string]interface{}:
+			if seg.isIndex {
+				return nil, nil
+			}
+			value = v[seg.key]
+		case []interface{}:
+			if !seg.isIndex || seg.index >= len(v) {
+				return nil, nil
+			}
+			value = v[seg.index]
+		default:
+			return nil, nil
+		}
+	}
+	return value, nil
+}
+
+// csvCell formats a value for a CSV cell
+func csvCell(value interface{}) (string, error) {
+	switch v := unwrap(value).(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		var b strings.Builder
+		s := &encodeState{w: &b, opts: &encodeOptions{sortKeys: true, floatPrec: -1}}
+		s.value(v, 0)
+		s.flush()
+		return b.String(), s.err
+	}
+}
+
+// FromCSV reads CSV from r into an array of objects, one per row. Column
+// paths are unflattened, so a "user.name" column fills {"user": {"name":
+// ...}}. Empty cells are left out of the row's object, which is how
+// ToCSV writes null and missing values. Other cells are strings unless
+// InferTypes is set.
+func FromCSV(r io.Reader, opts CSVOptions) (*JSONValue, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = opts.comma()
+
+	columns := opts.Columns
+	if !opts.NoHeader {
+		header, err := cr.Read()
+		if err == io.EOF {
+			return &JSONValue{data: []interface{}{}}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			columns = header
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("cannot read CSV without a header or Columns")
+	}
+
+	rows := []interface{}{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) != len(columns) {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: expected %d fields, got %d", line, len(columns), len(record))
+		}
+
+		flat := make(map[string]*JSONValue, len(columns))
+		for i, cell := range record {
+			if cell == "" {
+				continue
+			}
+			flat[columns[i]] = &JSONValue{data: csvValue(cell, opts.InferTypes)}
+		}
+		row, err := unflatten(flat, opts.Flatten, len(columns))
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row.data)
+	}
+	return &JSONValue{data: rows}, nil
+}
+
+// csvValue converts a cell to a value, reading it as JSON when infer is
+// set and the cell is a JSON value other than a string
+func csvValue(cell string, infer bool) interface{} {
+	if !infer || cell != strings.TrimSpace(cell) {
+		return cell
+	}
+	value, err := parse([]byte(cell))
+	if _, isString := value.(string); err != nil || isString {
+		return cell
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package easyjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToCSV(t *testing.T) {
+	jv := mustLoads(t, `[
+		{"id": 1, "name": "Ann", "user": {"city": "Paris"}, "tags": ["a", "b"]},
+		{"id": 2.5, "name": "Bo, Jr.", "active": true, "note": null},
+		{}
+	]`)
+
+	var b strings.Builder
+	if err := jv.ToCSV(&b, CSVOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "active,id,name,note,tags.0,tags.1,user.city\n" +
+		",1,Ann,,a,b,Paris\n" +
+		"true,2.5,\"Bo, Jr.\",,,,\n" +
+		",,,,,,\n"
+	if b.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, b.String())
+	}
+
+	b.Reset()
+	if err := jv.ToCSV(&b, CSVOptions{Comma: '\t', Columns: []string{"name", "user", "tags.1", "missing.x"}}); err != nil {
+		t.Fatal(err)
+	}
+	want = "name\tuser\ttags.1\tmissing.x\n" +
+		"Ann\t\"{\"\"city\"\":\"\"Paris\"\"}\"\tb\t\n" +
+		"Bo, Jr.\t\t\t\n" +
+		"\t\t\t\n"
+	if b.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, b.String())
+	}
+
+	b.Reset()
+	if err := jv.ToCSV(&b, CSVOptions{NoHeader: true, Columns: []string{"id"}}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "1\n2.5\n\n" {
+		t.Errorf("NoHeader: got %q", b.String())
+	}
+
+	for _, bad := range []string{`{"a": 1}`, `[1]`, `[{"a": 1}, [2]]`} {
+		if err := mustLoads(t, bad).ToCSV(&b, CSVOptions{}); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+}
+
+func TestFromCSV(t *testing.T) {
+	in := "id,name,user.city,tags.0,tags.2,flag,zip,raw\n" +
+		"1,Ann,Paris,a,c,true,007,null\n" +
+		"2.5,\"Bo, Jr.\",,,,false,10115,\"{\"\"x\"\":[1]}\"\n"
+
+	got, err := FromCSV(strings.NewReader(in), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"flag":"true","id":"1","name":"Ann","raw":"null","tags":["a",null,"c"],"user":{"city":"Paris"},"zip":"007"},` +
+		`{"flag":"false","id":"2.5","name":"Bo, Jr.","raw":"{\"x\":[1]}","zip":"10115"}]`
+	if s := dumps(t, got); s != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, s)
+	}
+
+	got, err = FromCSV(strings.NewReader(in), CSVOptions{InferTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `[{"flag":true,"id":1,"name":"Ann","raw":null,"tags":["a",null,"c"],"user":{"city":"Paris"},"zip":"007"},` +
+		`{"flag":false,"id":2.5,"name":"Bo, Jr.","raw":{"x":[1]},"zip":10115}]`
+	if s := dumps(t, got); s != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, s)
+	}
+
+	got, err = FromCSV(strings.NewReader("a\t1\nb\t2\n"), CSVOptions{Comma: '\t', NoHeader: true, Columns: []string{"k", "v"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := dumps(t, got); s != `[{"k":"a","v":"1"},{"k":"b","v":"2"}]` {
+		t.Errorf("TSV: got %s", s)
+	}
+
+	if got, err := FromCSV(strings.NewReader(""), CSVOptions{}); err != nil || dumps(t, got) != `[]` {
+		t.Errorf("Expected empty array, got %v, %v", got, err)
+	}
+	for _, bad := range []struct {
+		in   string
+		opts CSVOptions
+	}{
+		{"a,a.b\n1,2\n", CSVOptions{}},
+		{"a,b\n1\n", CSVOptions{}},
+		{"a,\"b\n", CSVOptions{}},
+		{"1,2\n", CSVOptions{NoHeader: true}},
+		{"1,2\n", CSVOptions{NoHeader: true, Columns: []string{"a"}}},
+	} {
+		if _, err := FromCSV(strings.NewReader(bad.in), bad.opts); err == nil {
+			t.Errorf("Expected error for %q", bad.in)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	jv := mustLoads(t, `[
+		{"id": 1, "user": {"name": "Ann", "roles": ["admin", "dev"]}, "meta": {}, "ok": true},
+		{"id": 2, "user": {"name": "Bo\nLine"}, "score": -1.5e-7, "ok": false}
+	]`)
+	for _, opts := range []CSVOptions{
+		{InferTypes: true},
+		{InferTypes: true, Comma: '\t', Flatten: FlattenOptions{Separator: "/", IndexStyle: IndexBrackets}},
+	} {
+		var b strings.Builder
+		if err := jv.ToCSV(&b, opts); err != nil {
+			t.Fatal(err)
+		}
+		back, err := FromCSV(strings.NewReader(b.String()), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !back.Equal(jv, EqualOptions{}) {
+			t.Errorf("Round trip with %+v gave %s from\n%s", opts, dumps(t, back), b.String())
+		}
+	}
+}
@@ -10,6 +10,15 @@ import (
 // JSONValue represents a flexible JSON value that can be any type
 type JSONValue struct {
 	data interface{}
+
+	// parent and key record where a value returned by Get lives, so that
+	// operations which replace an array (Append, Extend, Delete) can store
+	// the new slice back into the container while it still holds the old one
+	parent *JSONValue
+	key    interface{}
+
+	// tree is set when the value was parsed with WithPreserveFormat
+	tree *syntaxTree
 }
 
 // Q provides a fluent query interface for chaining access
@@ -30,18 +39,19 @@ func New(data interface{}) *JSONValue {
 	return &JSONValue{data: data}
 }
 
-// Loads parses a JSON string and returns a JSONValue
-func Loads(jsonStr string) (*JSONValue, error) {
-	var data interface{}
-	err := json.Unmarshal([]byte(jsonStr), &data)
-	if err != nil {
-		return nil, err
-	}
-	return &JSONValue{data: data}, nil
+// Loads parses a JSON string and returns a JSONValue.
+// Without options it behaves exactly like encoding/json; options switch to
+// the configurable parser.
+func Loads(jsonStr string, opts ...ParseOption) (*JSONValue, error) {
+	return Load([]byte(jsonStr), opts...)
 }
 
 // Load parses JSON from a byte slice and returns a JSONValue
-func Load(jsonBytes []byte) (*JSONValue, error) {
+func Load(jsonBytes []byte, opts ...ParseOption) (*JSONValue, error) {
+	if len(opts) > 0 {
+		return parseDocument(jsonBytes, opts...)
+	}
+
 	var data interface{}
 	err := json.Unmarshal(jsonBytes, &data)
 	if err != nil {
@@ -73,26 +83,60 @@ func (jv *JSONValue) Dump() ([]byte, error) {
 	return json.Marshal(jv.data)
 }
 
-// Get retrieves a value by key (for objects) or index (for arrays)
+// Get retrieves a value by key (for objects) or index (for arrays).
+// Negative indexes count from the end of an array, so -1 is the last element.
 func (jv *JSONValue) Get(key interface{}) *JSONValue {
 	switch v := jv.data.(type) {
 	case map[string]interface{}:
 		if keyStr, ok := key.(string); ok {
 			if val, exists := v[keyStr]; exists {
-				return &JSONValue{data: val}
+				return &JSONValue{data: val, parent: jv, key: keyStr}
 			}
 		}
 	case []interface{}:
 		if keyInt, ok := key.(int); ok {
-			if keyInt >= 0 && keyInt < len(v) {
-				return &JSONValue{data: v[keyInt]}
+			if i, ok := resolveIndex(keyInt, len(v)); ok {
+				return &JSONValue{data: v[i], parent: jv, key: i}
 			}
 		}
 	}
 	return &JSONValue{data: nil}
 }
 
-// Set sets a value by key (for objects) or index (for arrays)
+// resolveIndex turns a possibly negative index into an offset into an
+// array of length n and reports whether it is in range
+func resolveIndex(index, n int) (int, bool) {
+	if index < 0 {
+		index += n
+	}
+	return index, index >= 0 && index < n
+}
+
+// writeBack stores jv.data into the container jv was read from, replacing
+// old. It does nothing once the slot holds something else, as it does
+// after the parent deletes, replaces or shifts the value.
+func (jv *JSONValue) writeBack(old interface{}) {
+	if jv.parent == nil {
+		return
+	}
+	var current interface{}
+	switch v := jv.parent.data.(type) {
+	case map[string]interface{}:
+		if k, ok := jv.key.(string); ok {
+			current = v[k]
+		}
+	case []interface{}:
+		if i, ok := jv.key.(int); ok && i < len(v) {
+			current = v[i]
+		}
+	}
+	if sameContainer(current, old) {
+		jv.parent.Set(jv.key, jv.data)
+	}
+}
+
+// Set sets a value by key (for objects) or index (for arrays). Negative
+// indexes count from the end of an array.
 func (jv *JSONValue) Set(key interface{}, value interface{}) error {
 	switch v := jv.data.(type) {
 	case map[string]interface{}:
@@ -103,8 +147,8 @@ func (jv *JSONValue) Set(key interface{}, value interface{}) error {
 		return fmt.Errorf("key must be string for object")
 	case []interface{}:
 		if keyInt, ok := key.(int); ok {
-			if keyInt >= 0 && keyInt < len(v) {
-				v[keyInt] = value
+			if i, ok := resolveIndex(keyInt, len(v)); ok {
+				v[i] = value
 				return nil
 			}
 			return fmt.Errorf("index out of range")
@@ -125,7 +169,8 @@ func (jv *JSONValue) Has(key interface{}) bool {
 		}
 	case []interface{}:
 		if keyInt, ok := key.(int); ok {
-			return keyInt >= 0 && keyInt < len(v)
+			_, ok := resolveIndex(keyInt, len(v))
+			return ok
 		}
 	}
 	return false
@@ -142,11 +187,11 @@ func (jv *JSONValue) Delete(key interface{}) error {
 		return fmt.Errorf("key must be string for object")
 	case []interface{}:
 		if keyInt, ok := key.(int); ok {
-			if keyInt >= 0 && keyInt < len(v) {
+			if i, ok := resolveIndex(keyInt, len(v)); ok {
 				// Remove element at index
-				copy(v[keyInt:], v[keyInt+1:])
-				v = v[:len(v)-1]
-				jv.data = v
+				copy(v[i:], v[i+1:])
+				jv.data = v[:len(v)-1]
+				jv.writeBack(v)
 				return nil
 			}
 			return fmt.Errorf("index out of range")
@@ -345,6 +390,7 @@ func (jv *JSONValue) String() string {
 func (jv *JSONValue) Append(value interface{}) error {
 	if arr, ok := jv.data.([]interface{}); ok {
 		jv.data = append(arr, value)
+		jv.writeBack(arr)
 		return nil
 	}
 	return fmt.Errorf("cannot append to non-array type")
@@ -354,6 +400,7 @@ func (jv *JSONValue) Append(value interface{}) error {
 func (jv *JSONValue) Extend(values []interface{}) error {
 	if arr, ok := jv.data.([]interface{}); ok {
 		jv.data = append(arr, values...)
+		jv.writeBack(arr)
 		return nil
 	}
 	return fmt.Errorf("cannot extend non-array type")
@@ -373,19 +420,10 @@ func (jv *JSONValue) Update(other *JSONValue) error {
 	return fmt.Errorf("cannot update non-object type")
 }
 
-// Clone creates a deep copy of the JSONValue
+// Clone creates a deep copy of the JSONValue. Values keep their Go types,
+// so an int stays an int rather than becoming a float64.
 func (jv *JSONValue) Clone() *JSONValue {
-	bytes, err := json.Marshal(jv.data)
-	if err != nil {
-		return &JSONValue{data: nil}
-	}
-
-	var cloned interface{}
-	if err := json.Unmarshal(bytes, &cloned); err != nil {
-		return &JSONValue{data: nil}
-	}
-
-	return &JSONValue{data: cloned}
+	return &JSONValue{data: deepCopy(jv.data)}
 }
 
 // Path retrieves a nested value using a dot-separated path
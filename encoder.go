@@ -0,0 +1,436 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Encoder writes JSONValues to an io.Writer. By default it produces the
+// same output as Dumps: compact, keys sorted, HTML characters escaped.
+type Encoder struct {
+	w    io.Writer
+	opts encodeOptions
+}
+
+type encodeOptions struct {
+	prefix     string
+	indent     string
+	escapeHTML bool
+	sortKeys   bool
+	keyCompare func(a, b string) int
+	floatFmt   byte
+	floatPrec  int
+	ascii      bool
+	omitNulls  bool
+	theme      *Theme
+}
+
+// NewEncoder returns an Encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, opts: encodeOptions{escapeHTML: true, sortKeys: true, floatPrec: -1}}
+}
+
+// SetIndent makes the encoder put every element on its own line, starting
+// with prefix and followed by one copy of indent per nesting level
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.opts.prefix = prefix
+	e.opts.indent = indent
+}
+
+// SetEscapeHTML controls whether <, > and & are escaped inside strings
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.opts.escapeHTML = on
+}
+
+// SetSortKeys controls whether object keys are written in sorted order;
+// with sorting off they follow Go's map iteration order
+func (e *Encoder) SetSortKeys(on bool) {
+	e.opts.sortKeys = on
+}
+
+// SetKeyCompare orders object keys with cmp, which returns a negative
+// number when a sorts before b, zero when equal and positive otherwise.
+// A nil cmp restores the default lexical order.
+func (e *Encoder) SetKeyCompare(cmp func(a, b string) int) {
+	e.opts.keyCompare = cmp
+	if cmp != nil {
+		e.opts.sortKeys = true
+	}
+}
+
+// SetFloatFormat formats floating point numbers with strconv.FormatFloat
+// using format ('f', 'e', 'g', ...) and prec. A zero format restores the
+// encoding/json formatting.
+func (e *Encoder) SetFloatFormat(format byte, prec int) {
+	e.opts.floatFmt = format
+	e.opts.floatPrec = prec
+}
+
+// SetASCII controls whether non-ASCII characters are escaped as \uXXXX
+func (e *Encoder) SetASCII(on bool) {
+	e.opts.ascii = on
+}
+
+// SetOmitNulls controls whether object members whose value is null are left out
+func (e *Encoder) SetOmitNulls(on bool) {
+	e.opts.omitNulls = on
+}
+
+// Encode writes jv followed by a newline. Output is streamed to the
+// underlying writer in small chunks rather than built up in memory.
+func (e *Encoder) Encode(jv *JSONValue) error {
+	s := &encodeState{w: e.w, opts: &e.opts}
+	s.value(jv.data, 0)
+	s.buf = append(s.buf, '\n')
+	s.flush()
+	return s.err
+}
+
+// encodeFlushSize is how much output an encodeState buffers before
+// writing it out
+const encodeFlushSize = 4096
+
+type encodeState struct {
+	w    io.Writer
+	opts *encodeOptions
+	buf  []byte
+	err  error
+}
+
+func (s *encodeState) flush() {
+	if s.err == nil && len(s.buf) > 0 {
+		_, s.err = s.w.Write(s.buf)
+	}
+	s.buf = s.buf[:0]
+}
+
+func (s *encodeState) newline(depth int) {
+	if s.opts.indent == "" && s.opts.prefix == "" {
+		return
+	}
+	s.buf = append(s.buf, '\n')
+	s.buf = append(s.buf, s.opts.prefix...)
+	for i := 0; i < depth; i++ {
+		s.buf = append(s.buf, s.opts.indent...)
+	}
+}
+
+func (s *encodeState) value(value interface{}, depth int) {
+	if s.err != nil {
+		return
+	}
+	if len(s.buf) > encodeFlushSize {
+		s.flush()
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.object(v, depth)
+	case []interface{}:
+		s.array(v, depth)
+	case *JSONValue:
+		if v == nil {
+			s.value(nil, depth)
+		} else {
+			s.value(v.data, depth)
+		}
+	default:
+		start, end := len(s.buf), ""
+		if s.opts.theme != nil {
+			end = s.color(s.opts.theme.scalarColor(value))
+		}
+		buf, ok, err := s.opts.appendScalar(s.buf, value)
+		if err != nil {
+			s.err = err
+			return
+		}
+		if ok {
+			s.buf = append(buf, end...)
+			return
+		}
+		s.buf = s.buf[:start]
+		generic, err := genericValue(value)
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.value(generic, depth)
+	}
+}
+
+// genericValue converts a Go value outside the JSONValue data model to
+// maps, slices and scalars through its encoding/json form
+func genericValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// appendScalar writes null, booleans, strings and numbers. It reports
+// false for any other value.
+func (o *encodeOptions) appendScalar(buf []byte, value interface{}) ([]byte, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...), true, nil
+	case bool:
+		return strconv.AppendBool(buf, v), true, nil
+	case string:
+		return o.appendString(buf, v), true, nil
+	case json.Number:
+		if v == "" {
+			// encoding/json writes the zero Number as 0
+			v = "0"
+		}
+		if !isValidNumber(string(v)) {
+			return nil, true, fmt.Errorf("invalid number literal %q", v)
+		}
+		return append(buf, v...), true, nil
+	case float64:
+		return o.appendNumber(buf, v, 64)
+	case float32:
+		return o.appendNumber(buf, float64(v), 32)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10), true, nil
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10), true, nil
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10), true, nil
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10), true, nil
+	case int64:
+		return strconv.AppendInt(buf, v, 10), true, nil
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10), true, nil
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10), true, nil
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10), true, nil
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10), true, nil
+	case uint64:
+		return strconv.AppendUint(buf, v, 10), true, nil
+	}
+	return buf, false, nil
+}
+
+// isValidNumber reports whether s follows the JSON number grammar, as
+// encoding/json requires of a json.Number
+func isValidNumber(s string) bool {
+	if s != "" && s[0] == '-' {
+		s = s[1:]
+	}
+	digits := func() bool {
+		n := 0
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		s = s[n:]
+		return n > 0
+	}
+	switch {
+	case s == "":
+		return false
+	case s[0] == '0':
+		s = s[1:]
+	case !digits():
+		return false
+	}
+	if s != "" && s[0] == '.' {
+		s = s[1:]
+		if !digits() {
+			return false
+		}
+	}
+	if s != "" && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s != "" && (s[0] == '+' || s[0] == '-') {
+			s = s[1:]
+		}
+		if !digits() {
+			return false
+		}
+	}
+	return s == ""
+}
+
+func (o *encodeOptions) appendNumber(buf []byte, f float64, bits int) ([]byte, bool, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, true, fmt.Errorf("unsupported value: %v", f)
+	}
+	return o.appendFloat(buf, f, bits), true, nil
+}
+
+func (s *encodeState) object(obj map[string]interface{}, depth int) {
+	keys := make([]string, 0, len(obj))
+	for k, v := range obj {
+		if s.opts.omitNulls && isNullValue(v) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if s.opts.keyCompare != nil {
+		sort.SliceStable(keys, func(i, j int) bool { return s.opts.keyCompare(keys[i], keys[j]) < 0 })
+	} else if s.opts.sortKeys {
+		sort.Strings(keys)
+	}
+
+	if len(keys) == 0 {
+		s.punct('{')
+		s.punct('}')
+		return
+	}
+	s.punct('{')
+	for i, k := range keys {
+		if i > 0 {
+			s.punct(',')
+		}
+		s.newline(depth + 1)
+		end := ""
+		if s.opts.theme != nil {
+			end = s.color(s.opts.theme.Key)
+		}
+		s.buf = s.opts.appendString(s.buf, k)
+		s.buf = append(s.buf, end...)
+		s.punct(':')
+		if s.opts.indent != "" || s.opts.prefix != "" {
+			s.buf = append(s.buf, ' ')
+		}
+		s.value(obj[k], depth+1)
+	}
+	s.newline(depth)
+	s.punct('}')
+}
+
+func (s *encodeState) array(arr []interface{}, depth int) {
+	if len(arr) == 0 {
+		s.punct('[')
+		s.punct(']')
+		return
+	}
+	s.punct('[')
+	for i, v := range arr {
+		if i > 0 {
+			s.punct(',')
+		}
+		s.newline(depth + 1)
+		s.value(v, depth+1)
+	}
+	s.newline(depth)
+	s.punct(']')
+}
+
+func isNullValue(v interface{}) bool {
+	if jv, ok := v.(*JSONValue); ok {
+		return jv == nil || jv.data == nil
+	}
+	return v == nil
+}
+
+// appendFloat formats f like encoding/json unless a float format is set
+func (o *encodeOptions) appendFloat(buf []byte, f float64, bits int) []byte {
+	if o.floatFmt != 0 {
+		return strconv.AppendFloat(buf, f, o.floatFmt, o.floatPrec, bits)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+// appendString writes s as a quoted JSON string, escaping like
+// encoding/json and optionally all non-ASCII characters
+func (o *encodeOptions) appendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!o.escapeHTML || (c != '<' && c != '>' && c != '&')) {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, `\n`...)
+			case '\r':
+				buf = append(buf, `\r`...)
+			case '\t':
+				buf = append(buf, `\t`...)
+			case '\b':
+				buf = append(buf, `\b`...)
+			case '\f':
+				buf = append(buf, `\f`...)
+			default:
+				buf = append(buf, `\u00`...)
+				buf = append(buf, hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			if o.ascii {
+				buf = append(buf, `\ufffd`...)
+			} else {
+				buf = append(buf, "\ufffd"...)
+			}
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' || o.ascii {
+			buf = append(buf, s[start:i]...)
+			buf = appendUnicodeEscape(buf, r)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// appendUnicodeEscape writes r as \uXXXX, using a surrogate pair outside
+// the Basic Multilingual Plane
+func appendUnicodeEscape(buf []byte, r rune) []byte {
+	if r > 0xffff {
+		r -= 0x10000
+		buf = appendUnicodeEscape(buf, 0xd800+(r>>10))
+		return appendUnicodeEscape(buf, 0xdc00+(r&0x3ff))
+	}
+	return append(buf, '\\', 'u',
+		hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+}
@@ -0,0 +1,144 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func encodeString(t *testing.T, jv *JSONValue, configure func(*Encoder)) string {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if configure != nil {
+		configure(enc)
+	}
+	if err := enc.Encode(jv); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func TestEncoderMatchesDumps(t *testing.T) {
+	jv, _ := Loads(`{"html": "<a href=\"x\">&</a>", "ctl": "\u0001\b\f\n\r\t", "sep": "  ",
+		"nums": [0, -1.5, 1e21, 1e-7, 123456789, 0.000001], "nested": {"z": null, "a": [true, false, {}, []]}}`)
+	jv.Set("ints", []interface{}{1, int64(-2), uint8(3), float32(0.1)})
+	jv.Set("invalid", "bad \xff byte")
+
+	want, err := json.Marshal(jv.Raw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := encodeString(t, jv, nil); got != string(want) {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	wantIndent, _ := json.MarshalIndent(jv.Raw(), ">", "\t")
+	got := encodeString(t, jv, func(e *Encoder) { e.SetIndent(">", "\t") })
+	if got != string(wantIndent) {
+		t.Errorf("Expected\n%s\ngot\n%s", wantIndent, got)
+	}
+}
+
+func TestEncoderOptions(t *testing.T) {
+	jv := New(map[string]interface{}{
+		"b":    "<é😀>",
+		"a":    3.14159,
+		"none": nil,
+		"list": []interface{}{nil, 1},
+	})
+
+	tests := []struct {
+		name      string
+		configure func(*Encoder)
+		want      string
+	}{
+		{"no html escaping", func(e *Encoder) { e.SetEscapeHTML(false) },
+			`{"a":3.14159,"b":"<é😀>","list":[null,1],"none":null}`},
+		{"ascii", func(e *Encoder) { e.SetASCII(true) },
+			`{"a":3.14159,"b":"\u003c\u00e9\ud83d\ude00\u003e","list":[null,1],"none":null}`},
+		{"float format", func(e *Encoder) { e.SetFloatFormat('f', 2) },
+			`{"a":3.14,"b":"\u003cé😀\u003e","list":[null,1],"none":null}`},
+		{"omit nulls", func(e *Encoder) { e.SetOmitNulls(true) },
+			`{"a":3.14159,"b":"\u003cé😀\u003e","list":[null,1]}`},
+		{"key compare", func(e *Encoder) {
+			e.SetKeyCompare(func(a, b string) int {
+				if d := len(b) - len(a); d != 0 {
+					return d
+				}
+				return strings.Compare(a, b)
+			})
+		}, `{"list":[null,1],"none":null,"a":3.14159,"b":"\u003cé😀\u003e"}`},
+	}
+
+	for _, test := range tests {
+		if got := encodeString(t, jv, test.configure); got != test.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", test.name, test.want, got)
+		}
+	}
+}
+
+func TestEncoderUnsortedKeys(t *testing.T) {
+	jv := New(map[string]interface{}{"a": 1, "b": 2, "c": 3})
+	got := encodeString(t, jv, func(e *Encoder) { e.SetSortKeys(false) })
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &parsed); err != nil || len(parsed) != 3 {
+		t.Errorf("Unexpected output %s (%v)", got, err)
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(New([]interface{}{1, math.NaN()})); err == nil {
+		t.Error("Expected error for NaN")
+	}
+	if err := NewEncoder(&buf).Encode(New(make(chan int))); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+
+	// json.Number text must be a JSON number, as Dumps requires
+	for _, n := range []json.Number{"abc", "-", "01", "1.", ".5", "+1", "1e", "0x10", "Inf", "1 "} {
+		value := New(map[string]interface{}{"n": n})
+		if err := NewEncoder(&buf).Encode(value); err == nil {
+			t.Errorf("Expected error for json.Number(%q)", n)
+		}
+		if _, err := value.Dumps(); err == nil {
+			t.Errorf("Expected Dumps error for json.Number(%q)", n)
+		}
+	}
+	for _, n := range []json.Number{"0", "-0", "12", "-1.5", "1e400", "2.5E-3", "1e+2"} {
+		got := encodeString(t, New(n), nil)
+		if got != string(n) {
+			t.Errorf("json.Number(%q): got %s", n, got)
+		}
+	}
+	if got := encodeString(t, New(json.Number("")), nil); got != "0" {
+		t.Errorf("Expected the empty json.Number to encode as 0, got %s", got)
+	}
+}
+
+func TestEncoderStreams(t *testing.T) {
+	items := make([]interface{}, 2000)
+	for i := range items {
+		items[i] = strings.Repeat("x", 10)
+	}
+	w := &countingWriter{}
+	if err := NewEncoder(w).Encode(NewArrayFrom(items)); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes < 2 {
+		t.Errorf("Expected output in several writes, got %d", w.writes)
+	}
+}
+
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
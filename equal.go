@@ -0,0 +1,102 @@
+package easyjson
+
+import (
+	"crypto/sha256"
+	"hash/fnv"
+	"math"
+)
+
+// EqualOptions configures Equal. The zero value compares exactly, apart
+// from treating numbers of any Go type as equal when their values are.
+type EqualOptions struct {
+	// Epsilon is the largest difference at which two numbers still count
+	// as equal
+	Epsilon float64
+
+	// UnorderedArrays compares arrays as multisets, ignoring element order
+	UnorderedArrays bool
+}
+
+// Equal reports whether the value is deeply equal to other. Objects are
+// equal when they have the same keys with equal values regardless of
+// order, and numbers compare by value, so New(1) equals a parsed 1.
+func (jv *JSONValue) Equal(other *JSONValue, opts EqualOptions) bool {
+	return equalValues(jv, other, &opts)
+}
+
+func equalValues(a, b interface{}, opts *EqualOptions) bool {
+	a, b = unwrap(a), unwrap(b)
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !equalValues(v, w, opts) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		if opts.UnorderedArrays {
+			return equalUnordered(x, y, opts)
+		}
+		for i := range x {
+			if !equalValues(x[i], y[i], opts) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if fa, ok := toFloat64(a); ok {
+		fb, ok := toFloat64(b)
+		if !ok {
+			return false
+		}
+		return fa == fb || math.Abs(fa-fb) <= opts.Epsilon
+	}
+	return typeRank(a) == typeRank(b) && compareValues(a, b) == 0
+}
+
+// equalUnordered matches each element of x with a distinct equal element
+// of y. With an epsilon the greedy matching can miss a pairing that only
+// a different assignment would find.
+func equalUnordered(x, y []interface{}, opts *EqualOptions) bool {
+	used := make([]bool, len(y))
+	for _, v := range x {
+		found := false
+		for j, w := range y {
+			if !used[j] && equalValues(v, w, opts) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns a stable 64-bit FNV-1a fingerprint of the value's
+// canonical form. Values that are Equal with default options hash the
+// same, across processes and program versions, so the result can be used
+// for deduplication and cache keys.
+func (jv *JSONValue) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(canonicalKey(jv.data)))
+	return h.Sum64()
+}
+
+// Hash256 is like Hash but returns the SHA-256 of the canonical form, for
+// when collisions must be practically impossible
+func (jv *JSONValue) Hash256() [32]byte {
+	return sha256.Sum256([]byte(canonicalKey(jv.data)))
+}
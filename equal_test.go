@@ -0,0 +1,81 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	parsed, _ := Loads(`{"a": 1, "b": [1.5, "x", null, true], "c": {}}`)
+	built := New(map[string]interface{}{
+		"c": map[string]interface{}{},
+		"b": []interface{}{float32(1.5), "x", nil, true},
+		"a": int64(1),
+	})
+
+	tests := []struct {
+		name string
+		a, b *JSONValue
+		opts EqualOptions
+		want bool
+	}{
+		{"numeric types", New(1), mustLoads(t, "1"), EqualOptions{}, true},
+		{"json.Number", New(json.Number("2.50")), New(2.5), EqualOptions{}, true},
+		{"key order and types", parsed, built, EqualOptions{}, true},
+		{"different number", New(1), New(1.0000001), EqualOptions{}, false},
+		{"epsilon", New(1), New(1.0000001), EqualOptions{Epsilon: 1e-6}, true},
+		{"epsilon too small", New(1), New(1.1), EqualOptions{Epsilon: 1e-6}, false},
+		{"number vs string", New(1), New("1"), EqualOptions{}, false},
+		{"null vs missing", mustLoads(t, `{"a": null}`), mustLoads(t, `{}`), EqualOptions{}, false},
+		{"extra key", mustLoads(t, `{"a": 1}`), mustLoads(t, `{"a": 1, "b": 2}`), EqualOptions{}, false},
+		{"array order", mustLoads(t, `[1, 2, 2]`), mustLoads(t, `[2, 1, 2]`), EqualOptions{}, false},
+		{"unordered", mustLoads(t, `[1, 2, 2]`), mustLoads(t, `[2, 1, 2]`), EqualOptions{UnorderedArrays: true}, true},
+		{"unordered counts", mustLoads(t, `[1, 1, 2]`), mustLoads(t, `[1, 2, 2]`), EqualOptions{UnorderedArrays: true}, false},
+		{"unordered nested", mustLoads(t, `[{"t": [1, 2]}, 3]`), mustLoads(t, `[3, {"t": [2, 1]}]`), EqualOptions{UnorderedArrays: true}, true},
+		{"array vs object", mustLoads(t, `[]`), mustLoads(t, `{}`), EqualOptions{}, false},
+		{"nulls", New(nil), mustLoads(t, `null`), EqualOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Equal(tt.b, tt.opts); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+		if got := tt.b.Equal(tt.a, tt.opts); got != tt.want {
+			t.Errorf("%s (reversed): expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	parsed, _ := Loads(`{"b": [1, 2.5, "x"], "a": {"n": null, "t": true}}`)
+	built := New(map[string]interface{}{
+		"a": map[string]interface{}{"t": true, "n": nil},
+		"b": []interface{}{int8(1), float32(2.5), "x"},
+	})
+
+	if parsed.Hash() != built.Hash() || parsed.Hash256() != built.Hash256() {
+		t.Error("Expected equal values to hash the same")
+	}
+	// FNV-1a of {"a":1}, pinned so accidental format changes are noticed
+	if got := New(map[string]interface{}{"a": 1}).Hash(); got != 0x9c3e82dd6fcae8b1 {
+		t.Errorf("Unexpected hash %#x", got)
+	}
+
+	different := []*JSONValue{New(nil), New(1), New("1"), New([]interface{}{1}), mustLoads(t, `{"b": [1, 2.5]}`)}
+	seen := map[uint64]bool{parsed.Hash(): true}
+	for _, v := range different {
+		if seen[v.Hash()] {
+			t.Errorf("Unexpected hash collision for %v", v)
+		}
+		seen[v.Hash()] = true
+	}
+}
+
+func mustLoads(t *testing.T, s string) *JSONValue {
+	t.Helper()
+	jv, err := Loads(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jv
+}
@@ -0,0 +1,326 @@
+package easyjson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// IndexStyle selects how FlattenMap writes array indexes
+type IndexStyle int
+
+const (
+	IndexDot      IndexStyle = iota // "items.0.name"
+	IndexBrackets                   // "items[0].name"
+)
+
+// FlattenOptions configures FlattenMap and Unflatten
+type FlattenOptions struct {
+	Separator  string // between path segments, "." when empty
+	IndexStyle IndexStyle
+
+	// Escape is written before characters of a key that would otherwise
+	// be read as structure: the escape itself, the first character of the
+	// separator, '[' with IndexBrackets, and a key made only of digits
+	// with IndexDot. It is '\\' when zero.
+	Escape rune
+
+	// DisableEscaping writes keys as they are. The result is easier to
+	// read but no longer round-trips keys containing the separator.
+	DisableEscaping bool
+}
+
+func (o FlattenOptions) withDefaults() FlattenOptions {
+	if o.Separator == "" {
+		o.Separator = "."
+	}
+	if o.Escape == 0 {
+		o.Escape = '\\'
+	}
+	return o
+}
+
+// FlattenMap converts the value into a map from paths to leaf values,
+// such as {"user.address.city": "Paris", "tags.0": "a"}. Scalars and
+// empty objects and arrays are leaves, so Unflatten restores the value
+// exactly. A scalar root is stored under the empty path.
+//
+// It is named FlattenMap because Flatten flattens nested arrays. Empty
+// object keys round-trip except at the root and, with IndexBrackets,
+// directly before an array index.
+func (jv *JSONValue) FlattenMap(opts FlattenOptions) map[string]*JSONValue {
+	opts = opts.withDefaults()
+	out := make(map[string]*JSONValue)
+	flattenInto(out, jv.data, "", true, &opts)
+	return out
+}
+
+func flattenInto(out map[string]*JSONValue, value interface{}, prefix string, root bool, opts *FlattenOptions) {
+	value = unwrap(value)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			for k, item := range v {
+				key := opts.escapeKey(k)
+				if !root {
+					key = prefix + opts.Separator + key
+				}
+				flattenInto(out, item, key, false, opts)
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, item := range v {
+				var key string
+				switch {
+				case opts.IndexStyle == IndexBrackets:
+					key = prefix + "[" + strconv.Itoa(i) + "]"
+				case root:
+					key = strconv.Itoa(i)
+				default:
+					key = prefix + opts.Separator + strconv.Itoa(i)
+				}
+				flattenInto(out, item, key, false, opts)
+			}
+			return
+		}
+	}
+	out[prefix] = &JSONValue{data: value}
+}
+
+func (o *FlattenOptions) escapeKey(key string) string {
+	if o.DisableEscaping {
+		return key
+	}
+	sep, _ := utf8.DecodeRuneInString(o.Separator)
+	var b strings.Builder
+	for i, r := range key {
+		special := r == o.Escape || r == sep || (r == '[' && o.IndexStyle == IndexBrackets) ||
+			(i == 0 && o.IndexStyle == IndexDot && isDigits(key))
+		if special {
+			b.WriteRune(o.Escape)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// flatSegment is one step of a flattened path: an object key or an
+// array index
+type flatSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath splits a flattened path into segments
+func (o *FlattenOptions) parsePath(path string) ([]flatSegment, error) {
+	if path == "" {
+		return nil, nil
+	}
+	brackets := o.IndexStyle == IndexBrackets
+	var segments []flatSegment
+	for i := 0; ; {
+		key, escaped, next, err := o.readKey(path, i)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+		switch {
+		case o.IndexStyle == IndexDot && !escaped && isDigits(key):
+			index, err := strconv.Atoi(key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q in path %q", key, path)
+			}
+			segments = append(segments, flatSegment{index: index, isIndex: true})
+		case key != "" || escaped || !brackets || i == len(path) || path[i] != '[':
+			segments = append(segments, flatSegment{key: key})
+		}
+
+		for brackets && i < len(path) && path[i] == '[' {
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in path %q", path)
+			}
+			digits := path[i+1 : i+end]
+			index, err := strconv.Atoi(digits)
+			if err != nil || !isDigits(digits) {
+				return nil, fmt.Errorf("invalid array index %q in path %q", digits, path)
+			}
+			segments = append(segments, flatSegment{index: index, isIndex: true})
+			i += end + 1
+		}
+
+		if i == len(path) {
+			return segments, nil
+		}
+		if !strings.HasPrefix(path[i:], o.Separator) {
+			return nil, fmt.Errorf("expected separator at offset %d in path %q", i, path)
+		}
+		i += len(o.Separator)
+	}
+}
+
+// readKey reads an object key starting at offset i, stopping at an
+// unescaped separator, an index bracket or the end of path
+func (o *FlattenOptions) readKey(path string, i int) (key string, escaped bool, next int, err error) {
+	var b strings.Builder
+	for i < len(path) {
+		if strings.HasPrefix(path[i:], o.Separator) || path[i] == '[' && o.IndexStyle == IndexBrackets {
+			break
+		}
+		r, size := utf8.DecodeRuneInString(path[i:])
+		if r == o.Escape && !o.DisableEscaping {
+			if i+size >= len(path) {
+				return "", false, 0, fmt.Errorf("dangling escape at end of path %q", path)
+			}
+			r, size = utf8.DecodeRuneInString(path[i+size:])
+			i += utf8.RuneLen(o.Escape)
+			escaped = true
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	return b.String(), escaped, i, nil
+}
+
+// Unflatten rebuilds a document from a map produced by FlattenMap with
+// the same options. Paths that disagree about the shape of the document,
+// such as "a" and "a.b", are an error even when "a" is null. Missing
+// array elements are null.
+func Unflatten(flat map[string]*JSONValue, opts FlattenOptions) (*JSONValue, error) {
+	// Every element of a flattened array has its own path, so a larger
+	// index cannot come from FlattenMap
+	return unflatten(flat, opts, len(flat))
+}
+
+// unflatten is Unflatten with array indexes limited to below maxIndex,
+// which keeps a stray large index from allocating a huge array
+func unflatten(flat map[string]*JSONValue, opts FlattenOptions, maxIndex int) (*JSONValue, error) {
+	opts = opts.withDefaults()
+	paths := make([]string, 0, len(flat))
+	for path := range flat {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var root interface{} = unset
+	for _, path := range paths {
+		segments, err := opts.parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, seg := range segments {
+			if seg.isIndex && seg.index >= maxIndex {
+				return nil, fmt.Errorf("path %q: array index %d out of range", path, seg.index)
+			}
+		}
+		var leaf interface{}
+		if flat[path] != nil {
+			leaf = deepCopy(flat[path].data)
+		}
+		if root, err = unflattenInsert(root, segments, leaf); err != nil {
+			return nil, fmt.Errorf("path %q: %w", path, err)
+		}
+	}
+	if root == unset {
+		root = make(map[string]interface{})
+	}
+	return &JSONValue{data: fillUnset(root)}, nil
+}
+
+// unsetValue marks a slot no path has assigned yet, so that a null leaf
+// still conflicts with a deeper path through it
+type unsetValue struct{}
+
+var unset interface{} = unsetValue{}
+
+// fillUnset replaces the unset array elements left below v with null
+func fillUnset(v interface{}) interface{} {
+	switch n := v.(type) {
+	case unsetValue:
+		return nil
+	case map[string]interface{}:
+		for k, child := range n {
+			n[k] = fillUnset(child)
+		}
+	case []interface{}:
+		for i, child := range n {
+			n[i] = fillUnset(child)
+		}
+	}
+	return v
+}
+
+// unflattenInsert places leaf at segments below node and returns the
+// updated node
+func unflattenInsert(node interface{}, segments []flatSegment, leaf interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		switch n := node.(type) {
+		case unsetValue:
+			return leaf, nil
+		case map[string]interface{}:
+			if l, ok := leaf.(map[string]interface{}); ok && len(l) == 0 {
+				return n, nil
+			}
+		case []interface{}:
+			if l, ok := leaf.([]interface{}); ok && len(l) == 0 {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("conflicts with another path")
+	}
+
+	seg := segments[0]
+	if seg.isIndex {
+		if node == unset {
+			node = make([]interface{}, 0)
+		}
+		arr, ok := node.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("array index used on a non-array")
+		}
+		for len(arr) <= seg.index {
+			arr = append(arr, unset)
+		}
+		child, err := unflattenInsert(arr[seg.index], segments[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		arr[seg.index] = child
+		return arr, nil
+	}
+
+	if node == unset {
+		node = make(map[string]interface{})
+	}
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("key %q used on a non-object", seg.key)
+	}
+	current, ok := obj[seg.key]
+	if !ok {
+		current = unset
+	}
+	child, err := unflattenInsert(current, segments[1:], leaf)
+	if err != nil {
+		return nil, err
+	}
+	obj[seg.key] = child
+	return obj, nil
+}
@@ -0,0 +1,132 @@
+package easyjson
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func flatString(t *testing.T, flat map[string]*JSONValue) string {
+	t.Helper()
+	parts := make([]string, 0, len(flat))
+	for k, v := range flat {
+		parts = append(parts, k+"="+dumps(t, v))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+func TestFlattenMap(t *testing.T) {
+	jv, _ := Loads(`{"user": {"name": "Ann", "address": {"city": "Paris"}}, "tags": ["a", {"b": null}], "empty": {}, "none": []}`)
+
+	tests := []struct {
+		name string
+		opts FlattenOptions
+		want string
+	}{
+		{"default", FlattenOptions{},
+			`empty={} none=[] tags.0="a" tags.1.b=null user.address.city="Paris" user.name="Ann"`},
+		{"brackets", FlattenOptions{IndexStyle: IndexBrackets},
+			`empty={} none=[] tags[0]="a" tags[1].b=null user.address.city="Paris" user.name="Ann"`},
+		{"separator", FlattenOptions{Separator: "__"},
+			`empty={} none=[] tags__0="a" tags__1__b=null user__address__city="Paris" user__name="Ann"`},
+	}
+	for _, tt := range tests {
+		if got := flatString(t, jv.FlattenMap(tt.opts)); got != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.want, got)
+		}
+	}
+
+	if got := flatString(t, New(5).FlattenMap(FlattenOptions{})); got != `=5` {
+		t.Errorf("scalar root: got %s", got)
+	}
+	if got := flatString(t, mustLoads(t, `[[1]]`).FlattenMap(FlattenOptions{IndexStyle: IndexBrackets})); got != `[0][0]=1` {
+		t.Errorf("array root: got %s", got)
+	}
+}
+
+func TestFlattenEscaping(t *testing.T) {
+	jv, _ := Loads(`{"a.b": 1, "c\\d": 2, "0": {"x[1]": 3}}`)
+
+	if got := flatString(t, jv.FlattenMap(FlattenOptions{})); got != `\0.x[1]=3 a\.b=1 c\\d=2` {
+		t.Errorf("dot escaping: got %s", got)
+	}
+	if got := flatString(t, jv.FlattenMap(FlattenOptions{IndexStyle: IndexBrackets, Escape: '~'})); got != `0.x~[1]=3 a~.b=1 c\d=2` {
+		t.Errorf("bracket escaping: got %s", got)
+	}
+	if got := flatString(t, jv.FlattenMap(FlattenOptions{DisableEscaping: true})); got != `0.x[1]=3 a.b=1 c\d=2` {
+		t.Errorf("no escaping: got %s", got)
+	}
+}
+
+func TestUnflattenRoundTrip(t *testing.T) {
+	docs := []string{
+		`{"user": {"name": "Ann", "address": {"city": "Paris"}}, "tags": ["a", {"b": null}, [], [[]]], "empty": {}}`,
+		`{"a.b": {"c\\d": [1, {"0": {"1": true}}]}, "x[0]": {"": 1}, "__": "_", "_a_": {"b_": 2}}`,
+		`[{}, [], null, [1, [2]]]`,
+		`"scalar"`,
+		`{}`,
+		`[]`,
+	}
+	styles := []FlattenOptions{
+		{},
+		{IndexStyle: IndexBrackets},
+		{Separator: "_"},
+		{Separator: "__", IndexStyle: IndexBrackets, Escape: '~'},
+		{Separator: "/", Escape: '%'},
+	}
+
+	for _, doc := range docs {
+		jv := mustLoads(t, doc)
+		for _, opts := range styles {
+			back, err := Unflatten(jv.FlattenMap(opts), opts)
+			if err != nil {
+				t.Errorf("%s with %+v: %v", doc, opts, err)
+				continue
+			}
+			if !back.Equal(jv, EqualOptions{}) {
+				t.Errorf("%s with %+v: round trip gave %s", doc, opts, dumps(t, back))
+			}
+		}
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	flat := map[string]*JSONValue{"a.2": New("c"), "a.0": New("x"), "b[1]": New(1), "c.d": New(map[string]interface{}{})}
+	got, err := Unflatten(flat, FlattenOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := dumps(t, got); s != `{"a":["x",null,"c"],"b[1]":1,"c":{"d":{}}}` {
+		t.Errorf("Unflatten: got %s", s)
+	}
+
+	// null leaves are kept, and missing array elements are null too
+	got, err = Unflatten(map[string]*JSONValue{"a.1": New(nil), "b": New(nil)}, FlattenOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := dumps(t, got); s != `{"a":[null,null],"b":null}` {
+		t.Errorf("Unflatten with nulls: got %s", s)
+	}
+
+	for _, bad := range []map[string]*JSONValue{
+		{"a": New(1), "a.b": New(2)},
+		{"a.0": New(1), "a.b": New(2)},
+		{"a.b": New(1), "a.0": New(2)},
+		{"a": New(nil), "a.b": New(1)},
+		{"a.b": New(1), "a": nil},
+		{"a.0": New(nil), "a.0.b": New(1)},
+		{"a.9": New(1)},
+		{`a\`: New(1)},
+	} {
+		if _, err := Unflatten(bad, FlattenOptions{}); err == nil {
+			t.Errorf("Expected error for %v", bad)
+		}
+	}
+	for _, bad := range []string{"a[", "a[x]", "a[0]b"} {
+		if _, err := Unflatten(map[string]*JSONValue{bad: New(1)}, FlattenOptions{IndexStyle: IndexBrackets}); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package easyjson
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// The helpers below work on arrays and return new JSONValues, leaving the
+// receiver unchanged. Elements are shared rather than copied; use Clone
+// first when the results will be modified. Called on anything other than
+// an array they behave as if it were an empty array.
+
+func (jv *JSONValue) elements() []interface{} {
+	arr, _ := jv.data.([]interface{})
+	return arr
+}
+
+// element wraps an array element like Get does
+func (jv *JSONValue) element(i int, value interface{}) *JSONValue {
+	return &JSONValue{data: value, parent: jv, key: i}
+}
+
+// unwrap returns the data of a *JSONValue and any other value unchanged
+func unwrap(value interface{}) interface{} {
+	if v, ok := value.(*JSONValue); ok {
+		if v == nil {
+			return nil
+		}
+		return v.data
+	}
+	return value
+}
+
+// Map returns an array of fn applied to each element
+func (jv *JSONValue) Map(fn func(v *JSONValue) interface{}) *JSONValue {
+	arr := jv.elements()
+	out := make([]interface{}, len(arr))
+	for i, item := range arr {
+		out[i] = unwrap(fn(jv.element(i, item)))
+	}
+	return &JSONValue{data: out}
+}
+
+// Filter returns an array of the elements for which fn returns true
+func (jv *JSONValue) Filter(fn func(v *JSONValue) bool) *JSONValue {
+	out := make([]interface{}, 0)
+	for i, item := range jv.elements() {
+		if fn(jv.element(i, item)) {
+			out = append(out, item)
+		}
+	}
+	return &JSONValue{data: out}
+}
+
+// Reduce folds the elements into a single value, starting from initial
+// and replacing the accumulator with fn's result for each element
+func (jv *JSONValue) Reduce(initial interface{}, fn func(acc, v *JSONValue) interface{}) *JSONValue {
+	acc := &JSONValue{data: unwrap(initial)}
+	for i, item := range jv.elements() {
+		acc = &JSONValue{data: unwrap(fn(acc, jv.element(i, item)))}
+	}
+	return acc
+}
+
+// Find returns the first element for which fn returns true, or null
+func (jv *JSONValue) Find(fn func(v *JSONValue) bool) *JSONValue {
+	for i, item := range jv.elements() {
+		if v := jv.element(i, item); fn(v) {
+			return v
+		}
+	}
+	return &JSONValue{data: nil}
+}
+
+// Any reports whether fn returns true for at least one element
+func (jv *JSONValue) Any(fn func(v *JSONValue) bool) bool {
+	for i, item := range jv.elements() {
+		if fn(jv.element(i, item)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Every reports whether fn returns true for all elements. It is named
+// Every because All is the iterator over values.
+func (jv *JSONValue) Every(fn func(v *JSONValue) bool) bool {
+	for i, item := range jv.elements() {
+		if !fn(jv.element(i, item)) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortBy returns the elements sorted by the values at one or more
+// dot-separated paths, as used by Path. Later paths break ties in earlier
+// ones, a leading "-" sorts a path in descending order and an empty path
+// compares the elements themselves. Equal elements keep their order.
+// Values of different types order as null, booleans, numbers, strings,
+// arrays, objects.
+func (jv *JSONValue) SortBy(paths ...string) *JSONValue {
+	out := append([]interface{}{}, jv.elements()...)
+	type sortKey struct {
+		path       string
+		descending bool
+	}
+	keys := make([]sortKey, len(paths))
+	for i, p := range paths {
+		keys[i] = sortKey{strings.TrimPrefix(p, "-"), strings.HasPrefix(p, "-")}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		for _, k := range keys {
+			c := compareValues(pathValue(out[i], k.path), pathValue(out[j], k.path))
+			if k.descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return &JSONValue{data: out}
+}
+
+// GroupBy returns an array of [value, elements] pairs, one for each
+// distinct value at path, in order of first appearance. Values compare by
+// their canonical JSON text, so 1 and 1.0 share a group but 1 and "1" do
+// not.
+func (jv *JSONValue) GroupBy(path string) *JSONValue {
+	out := make([]interface{}, 0)
+	index := make(map[string]int)
+	for _, item := range jv.elements() {
+		value := pathValue(item, path)
+		key := canonicalKey(value)
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, []interface{}{value, []interface{}{}})
+		}
+		pair := out[i].([]interface{})
+		pair[1] = append(pair[1].([]interface{}), item)
+	}
+	return &JSONValue{data: out}
+}
+
+// UniqueBy returns the elements with duplicate values at path removed,
+// keeping the first occurrence
+func (jv *JSONValue) UniqueBy(path string) *JSONValue {
+	seen := make(map[string]bool)
+	out := make([]interface{}, 0)
+	for _, item := range jv.elements() {
+		key := canonicalKey(pathValue(item, path))
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, item)
+		}
+	}
+	return &JSONValue{data: out}
+}
+
+// Chunk splits the array into arrays of size elements, the last one
+// holding whatever remains. A size below one gives an empty array.
+func (jv *JSONValue) Chunk(size int) *JSONValue {
+	out := make([]interface{}, 0)
+	if size < 1 {
+		return &JSONValue{data: out}
+	}
+	arr := jv.elements()
+	for start := 0; start < len(arr); start += size {
+		end := min(start+size, len(arr))
+		out = append(out, append([]interface{}{}, arr[start:end]...))
+	}
+	return &JSONValue{data: out}
+}
+
+// Flatten splices nested arrays into the result up to depth levels deep.
+// A negative depth flattens completely.
+func (jv *JSONValue) Flatten(depth int) *JSONValue {
+	return &JSONValue{data: flattenArray(make([]interface{}, 0), jv.elements(), depth)}
+}
+
+func flattenArray(out, arr []interface{}, depth int) []interface{} {
+	for _, item := range arr {
+		if nested, ok := unwrap(item).([]interface{}); ok && depth != 0 {
+			out = flattenArray(out, nested, depth-1)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// Zip returns an array of arrays pairing the elements of this array with
+// those at the same index in others, as long as the shortest array
+func (jv *JSONValue) Zip(others ...*JSONValue) *JSONValue {
+	arrays := [][]interface{}{jv.elements()}
+	n := len(arrays[0])
+	for _, other := range others {
+		arr := other.elements()
+		arrays = append(arrays, arr)
+		n = min(n, len(arr))
+	}
+	out := make([]interface{}, n)
+	for i := range out {
+		tuple := make([]interface{}, len(arrays))
+		for j, arr := range arrays {
+			tuple[j] = arr[i]
+		}
+		out[i] = tuple
+	}
+	return &JSONValue{data: out}
+}
+
+// pathValue looks up a dot-separated path in value, returning the value
+// itself for an empty path
+func pathValue(value interface{}, path string) interface{} {
+	if path == "" {
+		return unwrap(value)
+	}
+	return (&JSONValue{data: unwrap(value)}).Path(path).data
+}
+
+// canonicalKey is a string that is equal for equal JSON values
+func canonicalKey(value interface{}) string {
+	data, err := appendCanonical(nil, value)
+	if err != nil {
+		// NaN, infinities and values encoding/json rejects
+		return fmt.Sprintf("%#v", value)
+	}
+	return string(data)
+}
+
+// typeRank orders values of different types for compareValues
+func typeRank(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	case map[string]interface{}:
+		return 5
+	}
+	if _, ok := toFloat64(value); ok {
+		return 2
+	}
+	return 6
+}
+
+// compareValues orders two values, returning a negative number, zero or a
+// positive number
+func compareValues(a, b interface{}) int {
+	a, b = unwrap(a), unwrap(b)
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch ra {
+	case 1:
+		ba, bb := a.(bool), b.(bool)
+		switch {
+		case ba == bb:
+			return 0
+		case bb:
+			return -1
+		}
+		return 1
+	case 2:
+		fa, _ := toFloat64(a)
+		fb, _ := toFloat64(b)
+		switch {
+		case fa < fb || math.IsNaN(fa) && !math.IsNaN(fb):
+			return -1
+		case fa > fb || math.IsNaN(fb) && !math.IsNaN(fa):
+			return 1
+		}
+		return 0
+	case 3:
+		return strings.Compare(a.(string), b.(string))
+	case 4:
+		x, y := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareValues(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	}
+	return strings.Compare(canonicalKey(a), canonicalKey(b))
+}
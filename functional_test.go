@@ -0,0 +1,154 @@
+package easyjson
+
+import (
+	"testing"
+)
+
+const people = `[
+	{"name": "Ann", "team": "red", "age": 31},
+	{"name": "Bob", "team": "blue", "age": 25},
+	{"name": "Cid", "team": "red", "age": 25},
+	{"name": "Dee", "team": "blue"},
+	{"name": "Eve", "team": "red", "age": 40}
+]`
+
+func dumps(t *testing.T, jv *JSONValue) string {
+	t.Helper()
+	s, err := jv.Dumps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestMapFilterReduce(t *testing.T) {
+	jv, _ := Loads(people)
+	before := dumps(t, jv)
+
+	names := jv.Map(func(v *JSONValue) interface{} { return v.Get("name") })
+	if got := dumps(t, names); got != `["Ann","Bob","Cid","Dee","Eve"]` {
+		t.Errorf("Map: got %s", got)
+	}
+
+	red := jv.Filter(func(v *JSONValue) bool { return v.Get("team").AsString() == "red" })
+	if red.Len() != 3 {
+		t.Errorf("Filter: expected 3 red, got %d", red.Len())
+	}
+
+	total := jv.Reduce(0, func(acc, v *JSONValue) interface{} {
+		return acc.AsFloat() + v.Get("age").AsFloat()
+	})
+	if total.AsInt() != 121 {
+		t.Errorf("Reduce: expected 121, got %v", total.Raw())
+	}
+
+	if dumps(t, jv) != before {
+		t.Error("Helpers modified the receiver")
+	}
+	if New("x").Map(func(v *JSONValue) interface{} { return 1 }).Len() != 0 {
+		t.Error("Expected non-arrays to act as empty")
+	}
+}
+
+func TestFindAnyEvery(t *testing.T) {
+	jv, _ := Loads(people)
+	hasAge := func(v *JSONValue) bool { return v.Has("age") }
+
+	if got := jv.Find(func(v *JSONValue) bool { return v.Get("age").AsInt() == 25 }); got.Get("name").AsString() != "Bob" {
+		t.Errorf("Find: got %v", got)
+	}
+	if !jv.Find(func(v *JSONValue) bool { return false }).IsNull() {
+		t.Error("Find: expected null when nothing matches")
+	}
+	if !jv.Any(hasAge) || jv.Every(hasAge) {
+		t.Error("Any/Every: wrong result")
+	}
+	if !NewArray().Every(hasAge) || NewArray().Any(hasAge) {
+		t.Error("Any/Every: wrong result for empty array")
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	jv, _ := Loads(people)
+	names := func(v *JSONValue) string {
+		return dumps(t, v.Map(func(v *JSONValue) interface{} { return v.Get("name") }))
+	}
+
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"age"}, `["Dee","Bob","Cid","Ann","Eve"]`},
+		{[]string{"-age"}, `["Eve","Ann","Bob","Cid","Dee"]`},
+		{[]string{"team", "-age"}, `["Bob","Dee","Eve","Ann","Cid"]`},
+		{nil, `["Ann","Bob","Cid","Dee","Eve"]`},
+	}
+	for _, tt := range tests {
+		if got := names(jv.SortBy(tt.paths...)); got != tt.want {
+			t.Errorf("SortBy(%v): expected %s, got %s", tt.paths, tt.want, got)
+		}
+	}
+
+	mixed, _ := Loads(`["b", 2, null, [1], true, {"a": 1}, "a", 1, false, [0, 5]]`)
+	if got := dumps(t, mixed.SortBy("")); got != `[null,false,true,1,2,"a","b",[0,5],[1],{"a":1}]` {
+		t.Errorf("SortBy mixed: got %s", got)
+	}
+}
+
+func TestGroupByUniqueBy(t *testing.T) {
+	jv, _ := Loads(people)
+
+	groups := jv.GroupBy("team")
+	if groups.Q(0, 0).AsString() != "red" || groups.Q(0, 1).Len() != 3 ||
+		groups.Q(1, 0).AsString() != "blue" || groups.Q(1, 1).Len() != 2 {
+		t.Errorf("GroupBy: got %s", dumps(t, groups))
+	}
+	byAge := jv.GroupBy("age")
+	if got := dumps(t, byAge.Map(func(v *JSONValue) interface{} { return []interface{}{v.Get(0), v.Get(1).Len()} })); got != `[[31,1],[25,2],[null,1],[40,1]]` {
+		t.Errorf("GroupBy age: got %s", got)
+	}
+	mixed, _ := Loads(`[{"k":1},{"k":"1"},{"k":null},{"k":"null"},{"k":true},{"k":"true"},{"k":1.0}]`)
+	if got := dumps(t, mixed.GroupBy("k").Map(func(v *JSONValue) interface{} { return []interface{}{v.Get(0), v.Get(1).Len()} })); got != `[[1,2],["1",1],[null,1],["null",1],[true,1],["true",1]]` {
+		t.Errorf("GroupBy mixed types: got %s", got)
+	}
+	if mixed.GroupBy("k").Len() != mixed.UniqueBy("k").Len() {
+		t.Error("GroupBy and UniqueBy disagree on distinct values")
+	}
+
+	unique := jv.UniqueBy("age")
+	if got := dumps(t, unique.Map(func(v *JSONValue) interface{} { return v.Get("name") })); got != `["Ann","Bob","Dee","Eve"]` {
+		t.Errorf("UniqueBy: got %s", got)
+	}
+	values, _ := Loads(`[1, "1", 1.0, [1], [1]]`)
+	if got := dumps(t, values.UniqueBy("")); got != `[1,"1",[1]]` {
+		t.Errorf("UniqueBy values: got %s", got)
+	}
+}
+
+func TestChunkFlattenZip(t *testing.T) {
+	jv, _ := Loads(`[1, 2, 3, 4, 5]`)
+	if got := dumps(t, jv.Chunk(2)); got != `[[1,2],[3,4],[5]]` {
+		t.Errorf("Chunk: got %s", got)
+	}
+	if got := dumps(t, jv.Chunk(0)); got != `[]` {
+		t.Errorf("Chunk(0): got %s", got)
+	}
+
+	nested, _ := Loads(`[1, [2, [3, [4]]], []]`)
+	for depth, want := range map[int]string{
+		0:  `[1,[2,[3,[4]]],[]]`,
+		1:  `[1,2,[3,[4]]]`,
+		2:  `[1,2,3,[4]]`,
+		-1: `[1,2,3,4]`,
+	} {
+		if got := dumps(t, nested.Flatten(depth)); got != want {
+			t.Errorf("Flatten(%d): expected %s, got %s", depth, want, got)
+		}
+	}
+
+	letters, _ := Loads(`["a", "b", "c"]`)
+	flags, _ := Loads(`[true, false]`)
+	if got := dumps(t, jv.Zip(letters, flags)); got != `[[1,"a",true],[2,"b",false]]` {
+		t.Errorf("Zip: got %s", got)
+	}
+}
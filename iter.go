@@ -0,0 +1,119 @@
+package easyjson
+
+import (
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The iterators below yield values that behave like those returned by
+// Get: appending to a yielded array updates the parent. Nothing is
+// allocated up front except the key slice SortedEntries sorts, so
+// breaking out of a loop early skips the remaining work.
+
+// All yields the values of an object or the elements of an array
+func (jv *JSONValue) All() iter.Seq[*JSONValue] {
+	return func(yield func(*JSONValue) bool) {
+		switch v := jv.data.(type) {
+		case map[string]interface{}:
+			for k, val := range v {
+				if !yield(&JSONValue{data: val, parent: jv, key: k}) {
+					return
+				}
+			}
+		case []interface{}:
+			for i, val := range v {
+				if !yield(&JSONValue{data: val, parent: jv, key: i}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Entries yields the key and value of each member of an object, in map
+// iteration order. It yields nothing for other types.
+func (jv *JSONValue) Entries() iter.Seq2[string, *JSONValue] {
+	return func(yield func(string, *JSONValue) bool) {
+		obj, _ := jv.data.(map[string]interface{})
+		for k, val := range obj {
+			if !yield(k, &JSONValue{data: val, parent: jv, key: k}) {
+				return
+			}
+		}
+	}
+}
+
+// SortedEntries is like Entries but yields keys in sorted order
+func (jv *JSONValue) SortedEntries() iter.Seq2[string, *JSONValue] {
+	return func(yield func(string, *JSONValue) bool) {
+		obj, _ := jv.data.(map[string]interface{})
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !yield(k, &JSONValue{data: obj[k], parent: jv, key: k}) {
+				return
+			}
+		}
+	}
+}
+
+// Elements yields the index and value of each element of an array. It
+// yields nothing for other types.
+func (jv *JSONValue) Elements() iter.Seq2[int, *JSONValue] {
+	return func(yield func(int, *JSONValue) bool) {
+		arr, _ := jv.data.([]interface{})
+		for i, val := range arr {
+			if !yield(i, &JSONValue{data: val, parent: jv, key: i}) {
+				return
+			}
+		}
+	}
+}
+
+// Walk yields every value in the tree depth-first, parents before their
+// children, together with its JSON Pointer (RFC 6901). The root comes
+// first with the pointer "". Object members are visited in map iteration
+// order.
+func (jv *JSONValue) Walk() iter.Seq2[string, *JSONValue] {
+	return func(yield func(string, *JSONValue) bool) {
+		walk(jv, "", yield)
+	}
+}
+
+func walk(jv *JSONValue, pointer string, yield func(string, *JSONValue) bool) bool {
+	if !yield(pointer, jv) {
+		return false
+	}
+	switch v := jv.data.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			child := &JSONValue{data: val, parent: jv, key: k}
+			if !walk(child, pointer+"/"+escapePointer(k), yield) {
+				return false
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			child := &JSONValue{data: val, parent: jv, key: i}
+			if !walk(child, pointer+"/"+strconv.Itoa(i), yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// pointerEscaper escapes a key for use as a JSON Pointer reference token
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointer(key string) string {
+	if !strings.ContainsAny(key, "~/") {
+		return key
+	}
+	return pointerEscaper.Replace(key)
+}
@@ -0,0 +1,120 @@
+package easyjson
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	jv, _ := Loads(`{"b": 2, "a": 1, "c": [10, 20, 30]}`)
+
+	sum := 0
+	for v := range jv.All() {
+		sum += v.AsInt()
+	}
+	if sum != 3 {
+		t.Errorf("Expected All to yield 2 and 1 plus an array, got sum %d", sum)
+	}
+
+	var keys []string
+	for k, v := range jv.Entries() {
+		if !jv.Get(k).IsArray() && v.AsInt() != jv.Get(k).AsInt() {
+			t.Errorf("Entries yielded wrong value for %s", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if strings.Join(keys, ",") != "a,b,c" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+
+	keys = nil
+	for k := range jv.SortedEntries() {
+		keys = append(keys, k)
+	}
+	if strings.Join(keys, ",") != "a,b,c" {
+		t.Errorf("Expected sorted keys, got %v", keys)
+	}
+
+	var elements []int
+	for i, v := range jv.Get("c").Elements() {
+		if i == 2 {
+			break
+		}
+		elements = append(elements, v.AsInt())
+	}
+	if len(elements) != 2 || elements[0] != 10 || elements[1] != 20 {
+		t.Errorf("Expected early break after two elements, got %v", elements)
+	}
+
+	for range New("scalar").Entries() {
+		t.Error("Expected no entries for a scalar")
+	}
+	for range jv.Elements() {
+		t.Error("Expected no elements for an object")
+	}
+	for range New(nil).All() {
+		t.Error("Expected nothing from null")
+	}
+}
+
+func TestIteratorsWriteBack(t *testing.T) {
+	jv, _ := Loads(`{"lists": {"x": [1]}}`)
+	for _, list := range jv.Get("lists").Entries() {
+		list.Append(2)
+	}
+	if s, _ := jv.Dumps(); s != `{"lists":{"x":[1,2]}}` {
+		t.Errorf("Expected append through iterator to update parent, got %s", s)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	jv, _ := Loads(`{"a": {"b": [true, {"c": null}]}, "x/y": 1, "m~n": 2}`)
+
+	got := map[string]string{}
+	var order []string
+	for pointer, v := range jv.Walk() {
+		s, _ := v.Dumps()
+		got[pointer] = s
+		order = append(order, pointer)
+	}
+	want := map[string]string{
+		"":         `{"a":{"b":[true,{"c":null}]},"m~n":2,"x/y":1}`,
+		"/a":       `{"b":[true,{"c":null}]}`,
+		"/a/b":     `[true,{"c":null}]`,
+		"/a/b/0":   `true`,
+		"/a/b/1":   `{"c":null}`,
+		"/a/b/1/c": `null`,
+		"/x~1y":    `1`,
+		"/m~0n":    `2`,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Expected %d values, got %v", len(want), got)
+	}
+	for pointer, s := range want {
+		if got[pointer] != s {
+			t.Errorf("%q: expected %s, got %s", pointer, s, got[pointer])
+		}
+	}
+
+	// Parents come before their children
+	seen := map[string]bool{}
+	for _, pointer := range order {
+		if i := strings.LastIndex(pointer, "/"); i >= 0 && !seen[pointer[:i]] {
+			t.Errorf("%q visited before its parent", pointer)
+		}
+		seen[pointer] = true
+	}
+
+	count := 0
+	for pointer := range jv.Walk() {
+		count++
+		if pointer == "/a/b" {
+			break
+		}
+	}
+	if count > len(want)-2 {
+		t.Errorf("Expected Walk to stop early, visited %d", count)
+	}
+}
@@ -0,0 +1,42 @@
+package easyjson
+
+import (
+	"encoding/json"
+)
+
+// MarshalJSON implements json.Marshaler so a JSONValue can be used as a
+// field in structs encoded with encoding/json
+func (jv JSONValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jv.data)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the value with the
+// decoded document
+func (jv *JSONValue) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*jv = JSONValue{data: value}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler using the compact JSON form
+func (jv JSONValue) MarshalText() ([]byte, error) {
+	return jv.MarshalJSON()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by parsing text as JSON
+func (jv *JSONValue) UnmarshalText(text []byte) error {
+	return jv.UnmarshalJSON(text)
+}
+
+// GobEncode implements gob.GobEncoder by encoding the value as JSON
+func (jv JSONValue) GobEncode() ([]byte, error) {
+	return jv.MarshalJSON()
+}
+
+// GobDecode implements gob.GobDecoder
+func (jv *JSONValue) GobDecode(data []byte) error {
+	return jv.UnmarshalJSON(data)
+}
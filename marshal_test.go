@@ -0,0 +1,103 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+type event struct {
+	Name    string     `json:"name"`
+	Payload *JSONValue `json:"payload"`
+	Meta    JSONValue  `json:"meta"`
+}
+
+func TestMarshalJSONField(t *testing.T) {
+	payload, _ := Loads(`{"id": 7, "tags": ["a", "b"]}`)
+	e := event{Name: "created", Payload: payload, Meta: *New("v1")}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"created","payload":{"id":7,"tags":["a","b"]},"meta":"v1"}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	var decoded event
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Payload.Get("tags").Get(1).AsString() != "b" || decoded.Meta.AsString() != "v1" {
+		t.Errorf("Unexpected decoded event %+v", decoded)
+	}
+
+	var empty event
+	if err := json.Unmarshal([]byte(`{"meta": null}`), &empty); err != nil {
+		t.Fatal(err)
+	}
+	if empty.Payload != nil || !empty.Meta.IsNull() {
+		t.Error("Expected null fields to decode as nil and null")
+	}
+	if err := json.Unmarshal([]byte(`{"payload": [1,]}`), &empty); err == nil {
+		t.Error("Expected error for invalid payload")
+	}
+}
+
+func TestMarshalNested(t *testing.T) {
+	inner := New(map[string]interface{}{"x": 1})
+	outer := New(map[string]interface{}{"inner": inner})
+	if s, _ := outer.Dumps(); s != `{"inner":{"x":1}}` {
+		t.Errorf("Expected nested JSONValue to marshal, got %s", s)
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	var jv JSONValue
+	if err := jv.UnmarshalText([]byte(`[1, "two"]`)); err != nil {
+		t.Fatal(err)
+	}
+	text, err := jv.MarshalText()
+	if err != nil || string(text) != `[1,"two"]` {
+		t.Errorf("Expected [1,\"two\"], got %s (%v)", text, err)
+	}
+
+	// encoding/xml only understands the text interfaces
+	type doc struct {
+		Value *JSONValue `xml:"value"`
+	}
+	data, err := xml.Marshal(doc{Value: &jv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back doc
+	if err := xml.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Value.Get(1).AsString() != "two" {
+		t.Errorf("Unexpected XML round trip %s", data)
+	}
+}
+
+func TestGob(t *testing.T) {
+	type record struct {
+		ID    int
+		Value *JSONValue
+	}
+	value, _ := Loads(`{"a": [true, null, 1.5]}`)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record{ID: 1, Value: value}); err != nil {
+		t.Fatal(err)
+	}
+	var got record
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := got.Value.Dumps(); got.ID != 1 || s != `{"a":[true,null,1.5]}` {
+		t.Errorf("Unexpected gob round trip %d %s", got.ID, s)
+	}
+}
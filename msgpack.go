@@ -0,0 +1,567 @@
+package easyjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// MsgpackOptions configures how MessagePack is read. The JSONValue model
+// has no binary or time type, so by default bin values become base64
+// strings and timestamps become RFC 3339 strings.
+type MsgpackOptions struct {
+	// KeepBinary keeps bin values as []byte, which DumpMsgpack writes
+	// back as bin
+	KeepBinary bool
+
+	// KeepTimestamps keeps timestamps as time.Time, which DumpMsgpack
+	// writes back as timestamps
+	KeepTimestamps bool
+}
+
+// msgpackTimestamp is the extension type MessagePack reserves for
+// timestamps
+const msgpackTimestamp = -1
+
+// DumpMsgpack encodes the value as MessagePack. Integral numbers are
+// written as the smallest integer type that holds them and other numbers
+// as float64, or float32 for float32 values. []byte values are written
+// as bin and time.Time values as timestamps. Object keys are sorted, so
+// equal values encode to the same bytes.
+func (jv *JSONValue) DumpMsgpack() ([]byte, error) {
+	buf, err := appendMsgpack(nil, jv.data)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// LoadMsgpack decodes a single MessagePack value. Integers become float64
+// when they fit in 53 bits and int64 or uint64 otherwise, so no precision
+// is lost. NaN, infinities and extension types other than timestamps are
+// rejected.
+func LoadMsgpack(data []byte, opts MsgpackOptions) (*JSONValue, error) {
+	r := bytes.NewReader(data)
+	d := &msgpackDecodeState{r: r, opts: opts}
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("trailing data after MessagePack value at offset %d", d.offset)
+	}
+	return &JSONValue{data: value}, nil
+}
+
+// MsgpackEncoder writes a stream of MessagePack values to an io.Writer
+type MsgpackEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewMsgpackEncoder returns a MsgpackEncoder that writes to w
+func NewMsgpackEncoder(w io.Writer) *MsgpackEncoder {
+	return &MsgpackEncoder{w: w}
+}
+
+// Encode writes the MessagePack encoding of jv. Values follow each other
+// without separators.
+func (e *MsgpackEncoder) Encode(jv *JSONValue) error {
+	buf, err := appendMsgpack(e.buf[:0], jv.data)
+	if err != nil {
+		return err
+	}
+	e.buf = buf
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// MsgpackDecoder reads a stream of MessagePack values from an io.Reader
+type MsgpackDecoder struct {
+	d msgpackDecodeState
+}
+
+// NewMsgpackDecoder returns a MsgpackDecoder that reads from r. It
+// buffers its input unless r is already an io.ByteReader.
+func NewMsgpackDecoder(r io.Reader, opts MsgpackOptions) *MsgpackDecoder {
+	src, ok := r.(msgpackSource)
+	if !ok {
+		src = bufio.NewReader(r)
+	}
+	return &MsgpackDecoder{d: msgpackDecodeState{r: src, opts: opts}}
+}
+
+// Decode reads the next value. It returns io.EOF when the stream ends
+// between values, and an error wrapping io.ErrUnexpectedEOF when it ends
+// inside one.
+func (dec *MsgpackDecoder) Decode() (*JSONValue, error) {
+	d := &dec.d
+	start := d.offset
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	d.offset++
+	value, err := d.valueOf(c, start)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONValue{data: value}, nil
+}
+
+// appendMsgpack writes the MessagePack encoding of value to buf
+func appendMsgpack(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if v {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case string:
+		buf, err := appendMsgpackLength(buf, len(v), 0xa0, 31, [3]byte{0xd9, 0xda, 0xdb})
+		return append(buf, v...), err
+	case []byte:
+		buf, err := appendMsgpackLength(buf, len(v), 0, 0, [3]byte{0xc4, 0xc5, 0xc6})
+		return append(buf, v...), err
+	case float64:
+		return appendMsgpackFloat(buf, v)
+	case float32:
+		if f := float64(v); f != math.Trunc(f) && !math.IsNaN(f) {
+			return binary.BigEndian.AppendUint32(append(buf, 0xca), math.Float32bits(v)), nil
+		}
+		return appendMsgpackFloat(buf, float64(v))
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendMsgpackInt(buf, n), nil
+		}
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendMsgpackUint(buf, n), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return appendMsgpackFloat(buf, f)
+	case int:
+		return appendMsgpackInt(buf, int64(v)), nil
+	case int8:
+		return appendMsgpackInt(buf, int64(v)), nil
+	case int16:
+		return appendMsgpackInt(buf, int64(v)), nil
+	case int32:
+		return appendMsgpackInt(buf, int64(v)), nil
+	case int64:
+		return appendMsgpackInt(buf, v), nil
+	case uint:
+		return appendMsgpackUint(buf, uint64(v)), nil
+	case uint8:
+		return appendMsgpackUint(buf, uint64(v)), nil
+	case uint16:
+		return appendMsgpackUint(buf, uint64(v)), nil
+	case uint32:
+		return appendMsgpackUint(buf, uint64(v)), nil
+	case uint64:
+		return appendMsgpackUint(buf, v), nil
+	case time.Time:
+		return appendMsgpackTime(buf, v), nil
+	case []interface{}:
+		buf, err := appendMsgpackLength(buf, len(v), 0x90, 15, [3]byte{0, 0xdc, 0xdd})
+		for _, item := range v {
+			if err != nil {
+				break
+			}
+			buf, err = appendMsgpack(buf, item)
+		}
+		return buf, err
+	case map[string]interface{}:
+		buf, err := appendMsgpackLength(buf, len(v), 0x80, 15, [3]byte{0, 0xde, 0xdf})
+		for _, k := range sortedKeys(v) {
+			if err != nil {
+				break
+			}
+			buf, _ = appendMsgpack(buf, k)
+			buf, err = appendMsgpack(buf, v[k])
+		}
+		return buf, err
+	case *JSONValue:
+		if v == nil {
+			return append(buf, 0xc0), nil
+		}
+		return appendMsgpack(buf, v.data)
+	}
+	generic, err := genericValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return appendMsgpack(buf, generic)
+}
+
+// appendMsgpackLength writes the type and length of a string, bin, array
+// or map: the fix form when n is at most fixMax, then the 8, 16 and
+// 32-bit forms. A zero code marks a form the type does not have.
+func appendMsgpackLength(buf []byte, n int, fix, fixMax byte, codes [3]byte) ([]byte, error) {
+	switch {
+	case fix != 0 && n <= int(fixMax):
+		return append(buf, fix|byte(n)), nil
+	case codes[0] != 0 && n <= math.MaxUint8:
+		return append(buf, codes[0], byte(n)), nil
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, codes[1]), uint16(n)), nil
+	case uint64(n) <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, codes[2]), uint32(n)), nil
+	}
+	return buf, fmt.Errorf("cannot encode length %d in MessagePack", n)
+}
+
+func appendMsgpackUint(buf []byte, n uint64) []byte {
+	switch {
+	case n <= 0x7f:
+		return append(buf, byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xcf), n)
+}
+
+func appendMsgpackInt(buf []byte, n int64) []byte {
+	switch {
+	case n >= 0:
+		return appendMsgpackUint(buf, uint64(n))
+	case n >= -32:
+		return append(buf, byte(n))
+	case n >= math.MinInt8:
+		return append(buf, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(n))
+}
+
+// appendMsgpackFloat writes f as an integer when it is integral and in
+// range, and as a float64 otherwise
+func appendMsgpackFloat(buf []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("unsupported value: %v", f)
+	}
+	if f == math.Trunc(f) && !(f == 0 && math.Signbit(f)) && f >= math.MinInt64 && f < 1<<64 {
+		if f < 0 {
+			return appendMsgpackInt(buf, int64(f)), nil
+		}
+		return appendMsgpackUint(buf, uint64(f)), nil
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(f)), nil
+}
+
+// appendMsgpackTime writes t in the smallest of the 32, 64 and 96-bit
+// timestamp formats that holds it
+func appendMsgpackTime(buf []byte, t time.Time) []byte {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case sec>>32 == 0 && nsec == 0:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd6, 0xff), uint32(sec))
+	case sec>>34 == 0:
+		return binary.BigEndian.AppendUint64(append(buf, 0xd7, 0xff), nsec<<34|uint64(sec))
+	}
+	buf = binary.BigEndian.AppendUint32(append(buf, 0xc7, 12, 0xff), uint32(nsec))
+	return binary.BigEndian.AppendUint64(buf, uint64(sec))
+}
+
+// msgpackSource is what the decoder reads from: a bytes.Reader for
+// LoadMsgpack and usually a bufio.Reader for a MsgpackDecoder
+type msgpackSource interface {
+	io.Reader
+	io.ByteReader
+}
+
+type msgpackDecodeState struct {
+	r      msgpackSource
+	opts   MsgpackOptions
+	offset int64
+	depth  int
+}
+
+func (d *msgpackDecodeState) errorAt(offset int64, format string, args ...interface{}) error {
+	return fmt.Errorf("%s at offset %d", fmt.Sprintf(format, args...), offset)
+}
+
+func (d *msgpackDecodeState) eof(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("unexpected end of MessagePack input at offset %d: %w", d.offset, io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+func (d *msgpackDecodeState) readByte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return 0, d.eof(err)
+	}
+	d.offset++
+	return c, nil
+}
+
+// read reads n bytes. Large lengths are read in pieces, so a corrupt
+// length cannot allocate more than the input holds.
+func (d *msgpackDecodeState) read(n uint64) ([]byte, error) {
+	if n <= 1<<16 {
+		buf := make([]byte, n)
+		m, err := io.ReadFull(d.r, buf)
+		d.offset += int64(m)
+		return buf, d.eof(err)
+	}
+	var b bytes.Buffer
+	m, err := io.CopyN(&b, d.r, int64(n))
+	d.offset += m
+	return b.Bytes(), d.eof(err)
+}
+
+// readUint reads a big-endian unsigned integer of size bytes
+func (d *msgpackDecodeState) readUint(size int) (uint64, error) {
+	buf, err := d.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range buf {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+func (d *msgpackDecodeState) value() (interface{}, error) {
+	start := d.offset
+	c, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	return d.valueOf(c, start)
+}
+
+// valueOf decodes the value whose type byte c was read at start
+func (d *msgpackDecodeState) valueOf(c byte, start int64) (interface{}, error) {
+	switch {
+	case c <= 0x7f:
+		return float64(c), nil
+	case c >= 0xe0:
+		return float64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.str(uint64(c&0x1f), start)
+	case c&0xf0 == 0x90:
+		return d.array(uint64(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.object(uint64(c & 0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.readUint(1 << (c - 0xcc))
+		if err != nil || n <= 1<<53 {
+			return float64(n), err
+		}
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+		return n, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		bits := 8 << (c - 0xd0)
+		u, err := d.readUint(bits / 8)
+		n := int64(u<<(64-bits)) >> (64 - bits)
+		if err != nil || n >= -1<<53 {
+			return float64(n), err
+		}
+		return n, nil
+	case 0xca, 0xcb:
+		u, err := d.readUint(4 << (c - 0xca))
+		if err != nil {
+			return nil, err
+		}
+		f := math.Float64frombits(u)
+		if c == 0xca {
+			f = float64(math.Float32frombits(uint32(u)))
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, d.errorAt(start, "%v cannot be represented in JSON", f)
+		}
+		return f, nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n, start)
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.read(n)
+		if err != nil || d.opts.KeepBinary {
+			return data, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(n)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1<<(c-0xd4), start)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readUint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(int(n), start)
+	}
+	return nil, d.errorAt(start, "invalid MessagePack type byte 0x%02x", c)
+}
+
+func (d *msgpackDecodeState) str(n uint64, start int64) (string, error) {
+	data, err := d.read(n)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(data) {
+		return "", d.errorAt(start, "invalid UTF-8 in string")
+	}
+	return string(data), nil
+}
+
+func (d *msgpackDecodeState) enter() error {
+	if d.depth++; d.depth > defaultMaxDepth {
+		return d.errorAt(d.offset, "exceeded max depth of %d", defaultMaxDepth)
+	}
+	return nil
+}
+
+func (d *msgpackDecodeState) array(n uint64) ([]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	arr := make([]interface{}, 0, min(n, 1024))
+	for ; n > 0; n-- {
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+	}
+	return arr, nil
+}
+
+// object decodes a map. String keys are used as they are and integer
+// keys in decimal; other keys are rejected.
+func (d *msgpackDecodeState) object(n uint64) (map[string]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	obj := make(map[string]interface{}, min(n, 1024))
+	for ; n > 0; n-- {
+		start := d.offset
+		c, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if !isMsgpackKey(c) {
+			return nil, d.errorAt(start, "unsupported MessagePack map key type 0x%02x", c)
+		}
+		key, err := d.valueOf(c, start)
+		if err != nil {
+			return nil, err
+		}
+		var k string
+		switch key := key.(type) {
+		case string:
+			k = key
+		case float64:
+			k = strconv.FormatFloat(key, 'f', -1, 64)
+		case int64:
+			k = strconv.FormatInt(key, 10)
+		case uint64:
+			k = strconv.FormatUint(key, 10)
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[k] = value
+	}
+	return obj, nil
+}
+
+// isMsgpackKey reports whether c starts a string or an integer, the types
+// accepted as map keys
+func isMsgpackKey(c byte) bool {
+	return c <= 0x7f || c >= 0xe0 || c&0xe0 == 0xa0 || c >= 0xd9 && c <= 0xdb || c >= 0xcc && c <= 0xd3
+}
+
+// ext decodes an extension value of size bytes. Only timestamps are
+// supported.
+func (d *msgpackDecodeState) ext(size int, start int64) (interface{}, error) {
+	typ, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.read(uint64(size))
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != msgpackTimestamp {
+		return nil, d.errorAt(start, "unsupported MessagePack extension type %d", int8(typ))
+	}
+
+	var sec int64
+	var nsec uint32
+	switch size {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(data))
+	case 8:
+		n := binary.BigEndian.Uint64(data)
+		sec, nsec = int64(n&(1<<34-1)), uint32(n>>34)
+	case 12:
+		nsec, sec = binary.BigEndian.Uint32(data), int64(binary.BigEndian.Uint64(data[4:]))
+	default:
+		return nil, d.errorAt(start, "invalid MessagePack timestamp length %d", size)
+	}
+	if nsec >= 1e9 {
+		return nil, d.errorAt(start, "invalid MessagePack timestamp nanoseconds %d", nsec)
+	}
+	t := time.Unix(sec, int64(nsec)).UTC()
+	if d.opts.KeepTimestamps {
+		return t, nil
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
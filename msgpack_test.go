@@ -0,0 +1,231 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDumpMsgpack(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "c0"},
+		{false, "c2"},
+		{true, "c3"},
+		{0, "00"},
+		{127, "7f"},
+		{128, "cc80"},
+		{256, "cd0100"},
+		{65536, "ce00010000"},
+		{uint64(1) << 32, "cf0000000100000000"},
+		{-1, "ff"},
+		{-32, "e0"},
+		{-33, "d0df"},
+		{-129, "d1ff7f"},
+		{-32769, "d2ffff7fff"},
+		{int64(math.MinInt64), "d38000000000000000"},
+		{float64(1e3), "cd03e8"},
+		{-2.0, "fe"},
+		{1.5, "cb3ff8000000000000"},
+		{math.Copysign(0, -1), "cb8000000000000000"},
+		{float32(0.5), "ca3f000000"},
+		{float32(2), "02"},
+		{json.Number("18446744073709551615"), "cfffffffffffffffff"},
+		{json.Number("1e2"), "64"},
+		{json.Number("0.25"), "cb3fd0000000000000"},
+		{"", "a0"},
+		{"a", "a161"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{[]byte{1, 2}, "c4020102"},
+		{[]interface{}{}, "90"},
+		{[]interface{}{1, "a", nil}, "9301a161c0"},
+		{map[string]interface{}{}, "80"},
+		{map[string]interface{}{"b": 1, "a": []interface{}{2}}, "82a1619102a16201"},
+		{time.Unix(1, 0), "d6ff00000001"},
+		{time.Unix(1, 5), "d7ff0000001400000001"},
+		{time.Unix(-1, 0), "c70cff00000000ffffffffffffffff"},
+		{New(3), "03"},
+		{struct {
+			A int `json:"a"`
+		}{7}, "81a16107"},
+	}
+	for _, tt := range tests {
+		out, err := New(tt.value).DumpMsgpack()
+		if err != nil {
+			t.Errorf("%v: %v", tt.value, err)
+			continue
+		}
+		if got := hex.EncodeToString(out); got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.value, tt.want, got)
+		}
+	}
+
+	for _, bad := range []interface{}{math.NaN(), math.Inf(-1), float32(math.NaN()), []interface{}{math.Inf(1)}} {
+		if _, err := New(bad).DumpMsgpack(); err == nil {
+			t.Errorf("Expected error for %v", bad)
+		}
+	}
+}
+
+func TestLoadMsgpack(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"c0", `null`},
+		{"c3", `true`},
+		{"7f", `127`},
+		{"e0", `-32`},
+		{"d0df", `-33`},
+		{"cd0100", `256`},
+		{"d2ffff7fff", `-32769`},
+		{"cfffffffffffffffff", `18446744073709551615`},
+		{"d38000000000000000", `-9223372036854775808`},
+		{"cf0020000000000001", `9007199254740993`},
+		{"ca3f000000", `0.5`},
+		{"cb3ff8000000000000", `1.5`},
+		{"a3616263", `"abc"`},
+		{"d903616263", `"abc"`},
+		{"da0003616263", `"abc"`},
+		{"db00000003616263", `"abc"`},
+		{"c403010203", `"AQID"`},
+		{"c500020102", `"AQI="`},
+		{"9301a161c0", `[1,"a",null]`},
+		{"dc000190", `[[]]`},
+		{"dd0000000180", `[{}]`},
+		{"82a16101a16292c2c3", `{"a":1,"b":[false,true]}`},
+		{"de0002a178ff01a161", `{"1":"a","x":-1}`},
+		{"df00000001d0df80", `{"-33":{}}`},
+		{"d6ff00000001", `"1970-01-01T00:00:01Z"`},
+		{"d7ff0000001400000001", `"1970-01-01T00:00:01.000000005Z"`},
+		{"c70cff00000000ffffffffffffffff", `"1969-12-31T23:59:59Z"`},
+	}
+	for _, tt := range tests {
+		in, _ := hex.DecodeString(tt.in)
+		jv, err := LoadMsgpack(in, MsgpackOptions{})
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got := dumps(t, jv); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.in, tt.want, got)
+		}
+	}
+
+	in, _ := hex.DecodeString("82a162c40101a174d7ff0000001400000001")
+	jv, err := LoadMsgpack(in, MsgpackOptions{KeepBinary: true, KeepTimestamps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := jv.Get("b").Raw().([]byte); !ok || !bytes.Equal(b, []byte{1}) {
+		t.Errorf("Expected []byte, got %#v", jv.Get("b").Raw())
+	}
+	if ts, ok := jv.Get("t").Raw().(time.Time); !ok || !ts.Equal(time.Unix(1, 5)) {
+		t.Errorf("Expected time.Time, got %#v", jv.Get("t").Raw())
+	}
+	if out, err := jv.DumpMsgpack(); err != nil || !bytes.Equal(out, in) {
+		t.Errorf("Expected the input back, got %x, %v", out, err)
+	}
+}
+
+func TestLoadMsgpackErrors(t *testing.T) {
+	tests := []struct {
+		in, msg string
+	}{
+		{"", "unexpected end"},
+		{"c1", "invalid MessagePack type byte 0xc1 at offset 0"},
+		{"9201", "unexpected end"},
+		{"d90561", "unexpected end"},
+		{"0101", "trailing data after MessagePack value at offset 1"},
+		{"cb7ff8000000000000", "cannot be represented"},
+		{"ca7f800000", "cannot be represented"},
+		{"a1ff", "invalid UTF-8"},
+		{"d40100", "extension type 1"},
+		{"d5ff0000", "timestamp length 2"},
+		{"d7ffffffffff00000000", "nanoseconds"},
+		{"8190c0", "map key type 0x90"},
+		{"81c0c0", "map key type 0xc0"},
+		{"91c4ffff", "unexpected end"},
+		{"c6ffffffff00", "unexpected end"},
+		{"ddffffffff", "unexpected end"},
+	}
+	for _, tt := range tests {
+		in, _ := hex.DecodeString(tt.in)
+		_, err := LoadMsgpack(in, MsgpackOptions{})
+		if err == nil || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: expected %q, got %v", tt.in, tt.msg, err)
+		}
+	}
+
+	deep := bytes.Repeat([]byte{0x91}, defaultMaxDepth+1)
+	if _, err := LoadMsgpack(deep, MsgpackOptions{}); err == nil || !strings.Contains(err.Error(), "max depth") {
+		t.Errorf("Expected depth error, got %v", err)
+	}
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	docs := []string{
+		`null`,
+		`{"name": "app", "ids": [1, -2, 300, 70000, 5000000000, -5000000000], "ratio": 0.25, "ok": true,
+			"nested": {"a": [[], {}, [{"b": null}]], "s": "é ü 😀"}, "big": 1e300, "small": -1e-300}`,
+		`[` + strings.Repeat(`"x",`, 20) + `{"` + strings.Repeat("k", 300) + `": "` + strings.Repeat("v", 70000) + `"}]`,
+	}
+	for _, doc := range docs {
+		jv := mustLoads(t, doc)
+		out, err := jv.DumpMsgpack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := LoadMsgpack(out, MsgpackOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !back.Equal(jv, EqualOptions{}) {
+			t.Errorf("Round trip of %.100s gave %.100s", doc, dumps(t, back))
+		}
+	}
+}
+
+func TestMsgpackStream(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewMsgpackEncoder(&buf)
+	values := []string{`{"seq": 1}`, `[1, 2]`, `"three"`, `null`}
+	for _, v := range values {
+		if err := enc.Encode(mustLoads(t, v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Encode(New(math.NaN())); err == nil {
+		t.Error("Expected error for NaN")
+	}
+
+	// hide the buffer's ByteReader so the decoder buffers its input
+	dec := NewMsgpackDecoder(struct{ io.Reader }{bytes.NewReader(buf.Bytes())}, MsgpackOptions{})
+	for _, want := range values {
+		jv, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := dumps(t, jv); got != dumps(t, mustLoads(t, want)) {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	dec = NewMsgpackDecoder(bytes.NewReader([]byte{0x01, 0x92, 0x01}), MsgpackOptions{})
+	if jv, err := dec.Decode(); err != nil || jv.AsInt() != 1 {
+		t.Fatalf("Expected 1, got %v, %v", jv, err)
+	}
+	if _, err := dec.Decode(); !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "offset 3") {
+		t.Errorf("Expected unexpected EOF at offset 3, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package easyjson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The dict-style helpers below take dot-separated paths like Path and
+// SetPath, so a plain key addresses a member of the receiver and
+// "user.address.city" a nested one. Numeric segments index arrays.
+
+// lookupPath walks to the container holding the last segment of path and
+// returns it with that segment as a key Get, Set and Delete understand
+func (jv *JSONValue) lookupPath(path string) (*JSONValue, interface{}, bool) {
+	var parts []string
+	for _, part := range strings.Split(path, ".") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, nil, false
+	}
+
+	current := jv
+	for _, part := range parts[:len(parts)-1] {
+		key := segmentKey(current, part)
+		if !current.Has(key) {
+			return nil, nil, false
+		}
+		current = current.Get(key)
+	}
+	return current, segmentKey(current, parts[len(parts)-1]), true
+}
+
+// segmentKey is the Get key for a path segment within container: an
+// index for arrays and the segment itself otherwise
+func segmentKey(container *JSONValue, part string) interface{} {
+	if _, ok := container.data.([]interface{}); ok {
+		if index, err := strconv.Atoi(part); err == nil {
+			return index
+		}
+	}
+	return part
+}
+
+// PopKey removes the value at path and returns it, or returns def when
+// there is nothing there, like Python's dict.pop
+func (jv *JSONValue) PopKey(path string, def interface{}) *JSONValue {
+	parent, key, ok := jv.lookupPath(path)
+	if !ok || !parent.Has(key) {
+		return &JSONValue{data: unwrap(def)}
+	}
+	value := parent.Get(key).data
+	parent.Delete(key)
+	return &JSONValue{data: value}
+}
+
+// SetDefault returns the value at path, first setting it to value if
+// there is nothing there, like Python's dict.setdefault. Missing
+// intermediate objects are created as by SetPath.
+func (jv *JSONValue) SetDefault(path string, value interface{}) *JSONValue {
+	if parent, key, ok := jv.lookupPath(path); ok && parent.Has(key) {
+		return parent.Get(key)
+	}
+	if err := jv.SetPath(path, unwrap(value)); err != nil {
+		return &JSONValue{data: nil}
+	}
+	parent, key, _ := jv.lookupPath(path)
+	return parent.Get(key)
+}
+
+// Pick returns a new object holding copies of the values at the given
+// paths, nested as in the receiver. Missing paths are left out, and
+// intermediate containers in the result are always objects.
+func (jv *JSONValue) Pick(paths ...string) *JSONValue {
+	out := make(map[string]interface{})
+	for _, path := range paths {
+		parent, key, ok := jv.lookupPath(path)
+		if !ok || !parent.Has(key) {
+			continue
+		}
+		value := deepCopy(parent.Get(key).data)
+
+		obj := out
+		parts := strings.Split(strings.Trim(path, "."), ".")
+		for _, part := range parts[:len(parts)-1] {
+			if part == "" {
+				continue
+			}
+			next, ok := obj[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				obj[part] = next
+			}
+			obj = next
+		}
+		obj[parts[len(parts)-1]] = value
+	}
+	return &JSONValue{data: out}
+}
+
+// Omit returns a copy of the value without the values at the given paths
+func (jv *JSONValue) Omit(paths ...string) *JSONValue {
+	out := jv.Clone()
+	for _, path := range paths {
+		if parent, key, ok := out.lookupPath(path); ok && parent.Has(key) {
+			parent.Delete(key)
+		}
+	}
+	return out
+}
+
+// RenameKey moves the object member at path to the key newKey in the
+// same object, replacing any member already there
+func (jv *JSONValue) RenameKey(path, newKey string) error {
+	parent, key, ok := jv.lookupPath(path)
+	if !ok || !parent.Has(key) {
+		return fmt.Errorf("key not found: %s", path)
+	}
+	obj, ok := parent.data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cannot rename in non-object type")
+	}
+	value := obj[key.(string)]
+	delete(obj, key.(string))
+	obj[newKey] = value
+	return nil
+}
+
+// Invert returns an object mapping each value of an object to its key.
+// String values are used as keys directly and other values by their
+// canonical JSON text. When values repeat, the key that sorts last wins.
+// Distinct values that would share a key, such as 1 and "1", are an error.
+func (jv *JSONValue) Invert() (*JSONValue, error) {
+	out := make(map[string]interface{})
+	values := make(map[string]string)
+	obj, _ := jv.data.(map[string]interface{})
+	for _, k := range sortedKeys(obj) {
+		value := unwrap(obj[k])
+		canonical := canonicalKey(value)
+		key, ok := value.(string)
+		if !ok {
+			key = canonical
+		}
+		if prev, seen := values[key]; seen && prev != canonical {
+			return nil, fmt.Errorf("values %s and %s both invert to key %q", prev, canonical, key)
+		}
+		values[key] = canonical
+		out[key] = k
+	}
+	return &JSONValue{data: out}, nil
+}
+
+// ToEntries returns the members of an object as an array of [key, value]
+// pairs sorted by key
+func (jv *JSONValue) ToEntries() *JSONValue {
+	obj, _ := jv.data.(map[string]interface{})
+	out := make([]interface{}, 0, len(obj))
+	for _, k := range sortedKeys(obj) {
+		out = append(out, []interface{}{k, obj[k]})
+	}
+	return &JSONValue{data: out}
+}
+
+// FromEntries builds an object from an array of [key, value] pairs, the
+// inverse of ToEntries. Later pairs replace earlier ones with the same key.
+func (jv *JSONValue) FromEntries() (*JSONValue, error) {
+	arr, ok := jv.data.([]interface{})
+	if !ok {
+		return &JSONValue{data: nil}, fmt.Errorf("cannot build entries from non-array type")
+	}
+	out := make(map[string]interface{}, len(arr))
+	for i, item := range arr {
+		pair, ok := unwrap(item).([]interface{})
+		if !ok || len(pair) != 2 {
+			return &JSONValue{data: nil}, fmt.Errorf("entry %d is not a [key, value] pair", i)
+		}
+		key, ok := unwrap(pair[0]).(string)
+		if !ok {
+			return &JSONValue{data: nil}, fmt.Errorf("entry %d has a non-string key", i)
+		}
+		out[key] = pair[1]
+	}
+	return &JSONValue{data: out}, nil
+}
@@ -0,0 +1,115 @@
+package easyjson
+
+import (
+	"testing"
+)
+
+const account = `{"id": 7, "user": {"name": "Ann", "password": "x", "emails": ["a@x", "b@x"]}, "plan": "pro"}`
+
+func TestPopKey(t *testing.T) {
+	jv, _ := Loads(account)
+
+	if v := jv.PopKey("plan", "free"); v.AsString() != "pro" || jv.Has("plan") {
+		t.Errorf("PopKey: got %v", v)
+	}
+	if v := jv.PopKey("plan", "free"); v.AsString() != "free" {
+		t.Errorf("PopKey default: got %v", v)
+	}
+	if v := jv.PopKey("user.password", nil); v.AsString() != "x" {
+		t.Errorf("PopKey path: got %v", v)
+	}
+	if v := jv.PopKey("user.emails.0", nil); v.AsString() != "a@x" {
+		t.Errorf("PopKey index: got %v", v)
+	}
+	if v := jv.PopKey("missing.deep", 1); v.AsInt() != 1 {
+		t.Errorf("PopKey missing path: got %v", v)
+	}
+	if got := dumps(t, jv); got != `{"id":7,"user":{"emails":["b@x"],"name":"Ann"}}` {
+		t.Errorf("PopKey: got %s", got)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	jv, _ := Loads(account)
+
+	if v := jv.SetDefault("plan", "free"); v.AsString() != "pro" {
+		t.Errorf("SetDefault existing: got %v", v)
+	}
+	if v := jv.SetDefault("settings.theme", "dark"); v.AsString() != "dark" {
+		t.Errorf("SetDefault new: got %v", v)
+	}
+	tags := jv.SetDefault("tags", []interface{}{})
+	tags.Append("new")
+	if got := dumps(t, jv.Get("tags")); got != `["new"]` {
+		t.Errorf("SetDefault should return a live value, got %s", got)
+	}
+	if jv.Path("settings.theme").AsString() != "dark" {
+		t.Error("SetDefault did not create intermediate object")
+	}
+}
+
+func TestPickOmit(t *testing.T) {
+	jv, _ := Loads(account)
+	before := dumps(t, jv)
+
+	picked := jv.Pick("id", "user.name", "user.emails.1", "missing")
+	if got := dumps(t, picked); got != `{"id":7,"user":{"emails":{"1":"b@x"},"name":"Ann"}}` {
+		t.Errorf("Pick: got %s", got)
+	}
+	omitted := jv.Omit("user.password", "user.emails.0", "plan", "missing.key")
+	if got := dumps(t, omitted); got != `{"id":7,"user":{"emails":["b@x"],"name":"Ann"}}` {
+		t.Errorf("Omit: got %s", got)
+	}
+	if dumps(t, jv) != before {
+		t.Error("Pick/Omit modified the receiver")
+	}
+}
+
+func TestRenameKey(t *testing.T) {
+	jv, _ := Loads(account)
+	if err := jv.RenameKey("user.name", "fullName"); err != nil {
+		t.Fatal(err)
+	}
+	if err := jv.RenameKey("plan", "id"); err != nil {
+		t.Fatal(err)
+	}
+	if got := dumps(t, jv); got != `{"id":"pro","user":{"emails":["a@x","b@x"],"fullName":"Ann","password":"x"}}` {
+		t.Errorf("RenameKey: got %s", got)
+	}
+	if err := jv.RenameKey("nope", "x"); err == nil {
+		t.Error("RenameKey: expected error for missing key")
+	}
+	if err := jv.RenameKey("user.emails.0", "x"); err == nil {
+		t.Error("RenameKey: expected error inside an array")
+	}
+}
+
+func TestInvertEntries(t *testing.T) {
+	jv, _ := Loads(`{"a": "x", "b": 1, "c": "x", "d": null}`)
+	inverted, err := jv.Invert()
+	if err != nil || dumps(t, inverted) != `{"1":"b","null":"d","x":"c"}` {
+		t.Errorf("Invert: got %v (%v)", inverted, err)
+	}
+	for _, input := range []string{`{"a": 1, "b": "1"}`, `{"a": null, "b": "null"}`, `{"a": true, "b": "true"}`} {
+		mixed, _ := Loads(input)
+		if _, err := mixed.Invert(); err == nil {
+			t.Errorf("Expected Invert of %s to fail", input)
+		}
+	}
+
+	entries := jv.ToEntries()
+	if got := dumps(t, entries); got != `[["a","x"],["b",1],["c","x"],["d",null]]` {
+		t.Errorf("ToEntries: got %s", got)
+	}
+	back, err := entries.FromEntries()
+	if err != nil || dumps(t, back) != dumps(t, jv) {
+		t.Errorf("FromEntries: got %s (%v)", dumps(t, back), err)
+	}
+
+	for _, src := range []string{`{}`, `[["a"]]`, `[[1, 2]]`, `[3]`} {
+		bad, _ := Loads(src)
+		if _, err := bad.FromEntries(); err == nil {
+			t.Errorf("FromEntries(%s): expected error", src)
+		}
+	}
+}
@@ -0,0 +1,946 @@
+package easyjson
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Syntax selects the input dialect accepted by the parser
+type Syntax int
+
+const (
+	// SyntaxJSON accepts strict RFC 8259 JSON
+	SyntaxJSON Syntax = iota
+	// SyntaxJSONC adds // and /* */ comments and trailing commas
+	SyntaxJSONC
+	// SyntaxJSON5 accepts JSON5: JSONC plus unquoted keys, single-quoted
+	// and multi-line strings, hexadecimal numbers, Infinity and NaN.
+	// Infinity and NaN load as float64 but cannot be dumped as JSON.
+	SyntaxJSON5
+)
+
+// DuplicateKeyPolicy controls how the parser treats an object key that
+// appears more than once
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeepLast keeps the last occurrence, matching encoding/json
+	DuplicateKeepLast DuplicateKeyPolicy = iota
+	// DuplicateKeepFirst keeps the first occurrence and ignores later ones
+	DuplicateKeepFirst
+	// DuplicateError fails with a *DuplicateKeyError
+	DuplicateError
+	// DuplicateCollect gathers every occurrence into an array
+	DuplicateCollect
+)
+
+// ParseOptions configures how Loads and Load parse their input.
+// Zero limits mean no limit, except MaxDepth which defaults to 10000 like
+// encoding/json.
+type ParseOptions struct {
+	Syntax        Syntax
+	DuplicateKeys DuplicateKeyPolicy
+
+	MaxDepth        int // maximum nesting of objects and arrays
+	MaxBytes        int // maximum size of the whole input
+	MaxStringLength int // maximum decoded length in bytes of any string or key
+	MaxObjectKeys   int // maximum number of members in one object
+	MaxArrayLength  int // maximum number of elements in one array
+
+	// PreserveFormat keeps the source text so DumpsPreserved can write
+	// edits back without disturbing comments, whitespace or key order
+	PreserveFormat bool
+}
+
+// ParseOption modifies ParseOptions
+type ParseOption func(*ParseOptions)
+
+// WithSyntax selects the input dialect
+func WithSyntax(syntax Syntax) ParseOption {
+	return func(o *ParseOptions) {
+		o.Syntax = syntax
+	}
+}
+
+// WithDuplicateKeys sets the policy for repeated object keys
+func WithDuplicateKeys(policy DuplicateKeyPolicy) ParseOption {
+	return func(o *ParseOptions) {
+		o.DuplicateKeys = policy
+	}
+}
+
+// WithMaxDepth limits how deeply objects and arrays may nest
+func WithMaxDepth(n int) ParseOption {
+	return func(o *ParseOptions) {
+		o.MaxDepth = n
+	}
+}
+
+// WithMaxBytes limits the size of the input
+func WithMaxBytes(n int) ParseOption {
+	return func(o *ParseOptions) {
+		o.MaxBytes = n
+	}
+}
+
+// WithMaxStringLength limits the decoded length of strings and keys
+func WithMaxStringLength(n int) ParseOption {
+	return func(o *ParseOptions) {
+		o.MaxStringLength = n
+	}
+}
+
+// WithMaxObjectKeys limits the number of members in each object
+func WithMaxObjectKeys(n int) ParseOption {
+	return func(o *ParseOptions) {
+		o.MaxObjectKeys = n
+	}
+}
+
+// WithMaxArrayLength limits the number of elements in each array
+func WithMaxArrayLength(n int) ParseOption {
+	return func(o *ParseOptions) {
+		o.MaxArrayLength = n
+	}
+}
+
+// WithPreserveFormat enables format-preserving round trips through
+// DumpsPreserved
+func WithPreserveFormat() ParseOption {
+	return func(o *ParseOptions) {
+		o.PreserveFormat = true
+	}
+}
+
+// WithParseOptions replaces all parse options at once
+func WithParseOptions(opts ParseOptions) ParseOption {
+	return func(o *ParseOptions) {
+		*o = opts
+	}
+}
+
+// Position identifies a location in the parser input
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // byte column, starting at 1
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// SyntaxError describes malformed input
+type SyntaxError struct {
+	Msg string
+	Pos Position
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at %s", e.Msg, e.Pos)
+}
+
+// DuplicateKeyError is returned when DuplicateError is in effect and an
+// object repeats a key
+type DuplicateKeyError struct {
+	Key    string
+	First  Position
+	Second Position
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %q at %s (first defined at %s)", e.Key, e.Second, e.First)
+}
+
+// Limit identifies one of the parser limits
+type Limit int
+
+const (
+	LimitDepth Limit = iota + 1
+	LimitBytes
+	LimitStringLength
+	LimitObjectKeys
+	LimitArrayLength
+)
+
+func (l Limit) String() string {
+	switch l {
+	case LimitDepth:
+		return "max depth"
+	case LimitBytes:
+		return "max bytes"
+	case LimitStringLength:
+		return "max string length"
+	case LimitObjectKeys:
+		return "max object keys"
+	case LimitArrayLength:
+		return "max array length"
+	}
+	return "unknown limit"
+}
+
+// LimitError is returned when the input exceeds one of the ParseOptions limits
+type LimitError struct {
+	Limit Limit
+	Max   int
+	Pos   Position
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s of %d exceeded at %s", e.Limit, e.Max, e.Pos)
+}
+
+// defaultMaxDepth mirrors the nesting limit of encoding/json
+const defaultMaxDepth = 10000
+
+// parse decodes data into the same Go types encoding/json produces
+func parse(data []byte, opts ...ParseOption) (interface{}, error) {
+	jv, err := parseDocument(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return jv.data, nil
+}
+
+// parseDocument parses data into a JSONValue, attaching the syntax tree
+// when PreserveFormat is set
+func parseDocument(data []byte, opts ...ParseOption) (*JSONValue, error) {
+	var o ParseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	p := &parser{data: data, opts: o}
+	if o.MaxBytes > 0 && len(data) > o.MaxBytes {
+		return nil, p.limitError(LimitBytes, o.MaxBytes, o.MaxBytes)
+	}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.data) {
+		return nil, p.errorf("invalid character %s after top-level value", quoteChar(p.data[p.pos]))
+	}
+
+	jv := &JSONValue{data: value}
+	if o.PreserveFormat {
+		jv.tree = newSyntaxTree(data, p.node)
+	}
+	return jv, nil
+}
+
+type parser struct {
+	data  []byte
+	pos   int
+	depth int
+	opts  ParseOptions
+
+	// node is the syntax node of the value parsed last, when
+	// PreserveFormat is set
+	node *cstNode
+}
+
+// position converts a byte offset into a Position
+func (p *parser) position(offset int) Position {
+	return positionAt(p.data, offset)
+}
+
+// positionAt converts a byte offset in data into a Position
+func positionAt(data []byte, offset int) Position {
+	pos := Position{Offset: offset, Line: 1, Column: 1}
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+func (p *parser) errorAt(offset int, format string, args ...interface{}) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Pos: p.position(offset)}
+}
+
+func (p *parser) limitError(limit Limit, max, offset int) error {
+	return &LimitError{Limit: limit, Max: max, Pos: p.position(offset)}
+}
+
+func (p *parser) unexpected() error {
+	if p.pos >= len(p.data) {
+		return p.errorf("unexpected end of JSON input")
+	}
+	return p.errorf("invalid character %s", quoteChar(p.data[p.pos]))
+}
+
+func quoteChar(c byte) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+	s := strconv.Quote(string(c))
+	return "'" + s[1:len(s)-1] + "'"
+}
+
+// skipSpace skips whitespace and, outside strict JSON, comments. An
+// unterminated block comment is an error.
+func (p *parser) skipSpace() error {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; c {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '/':
+			if p.opts.Syntax == SyntaxJSON || p.pos+1 >= len(p.data) {
+				return nil
+			}
+			switch p.data[p.pos+1] {
+			case '/':
+				end := bytes.IndexAny(p.data[p.pos:], "\n\r")
+				if end < 0 {
+					p.pos = len(p.data)
+				} else {
+					p.pos += end
+				}
+			case '*':
+				end := bytes.Index(p.data[p.pos+2:], []byte("*/"))
+				if end < 0 {
+					return p.errorf("unterminated comment")
+				}
+				p.pos += end + 4
+			default:
+				return nil
+			}
+		default:
+			if p.opts.Syntax != SyntaxJSON5 {
+				return nil
+			}
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			if r != '\v' && r != '\f' && r != '\ufeff' && r != '\u2028' && r != '\u2029' && !unicode.Is(unicode.Zs, r) {
+				return nil
+			}
+			p.pos += size
+		}
+	}
+	return nil
+}
+
+// trailingComma reports whether the closing delimiter follows a comma
+// that the current syntax allows to dangle
+func (p *parser) trailingComma(closing byte) bool {
+	return p.opts.Syntax != SyntaxJSON && p.pos < len(p.data) && p.data[p.pos] == closing
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	if !p.opts.PreserveFormat {
+		return p.parseAnyValue()
+	}
+	start := p.pos
+	p.node = nil
+	value, err := p.parseAnyValue()
+	if err == nil && p.node == nil {
+		p.node = &cstNode{start: start, end: p.pos, value: value}
+	}
+	return value, err
+}
+
+func (p *parser) parseAnyValue() (interface{}, error) {
+	if p.pos >= len(p.data) {
+		return nil, p.unexpected()
+	}
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseArray()
+	case c == '"':
+		return p.parseString()
+	case c == '\'' && p.opts.Syntax == SyntaxJSON5:
+		return p.parseString()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case p.opts.Syntax == SyntaxJSON5 && (c == '+' || c == '.' || c == 'I' || c == 'N'):
+		return p.parseNumber()
+	case c == 't':
+		return true, p.parseLiteral("true")
+	case c == 'f':
+		return false, p.parseLiteral("false")
+	case c == 'n':
+		return nil, p.parseLiteral("null")
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseLiteral(lit string) error {
+	for i := 0; i < len(lit); i++ {
+		if p.pos >= len(p.data) || p.data[p.pos] != lit[i] {
+			return p.unexpected()
+		}
+		p.pos++
+	}
+	return nil
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.opts.MaxDepth > 0 {
+		if p.depth > p.opts.MaxDepth {
+			return p.limitError(LimitDepth, p.opts.MaxDepth, p.pos)
+		}
+	} else if p.depth > defaultMaxDepth {
+		return p.errorf("exceeded max depth")
+	}
+	return nil
+}
+
+// leave undoes enter once a container has been parsed
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseObject() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	n := p.newNode()
+	p.pos++ // '{'
+	obj := make(map[string]interface{})
+	var seen map[string]int
+	var collected map[string]bool
+
+	lead := p.pos
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.closeNode(n, obj, lead, false)
+		return obj, nil
+	}
+	for count := 1; ; count++ {
+		if p.pos >= len(p.data) {
+			return nil, p.unexpected()
+		}
+		keyStart := p.pos
+		if p.opts.MaxObjectKeys > 0 && count > p.opts.MaxObjectKeys {
+			return nil, p.limitError(LimitObjectKeys, p.opts.MaxObjectKeys, keyStart)
+		}
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		keyEnd := p.pos
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.unexpected()
+		}
+		p.pos++
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		member := cstMember{key: key, lead: lead, start: keyStart, keyEnd: keyEnd, node: p.node, trail: p.pos}
+
+		if existing, dup := obj[key]; dup {
+			switch p.opts.DuplicateKeys {
+			case DuplicateKeepFirst:
+			case DuplicateError:
+				return nil, &DuplicateKeyError{
+					Key:    key,
+					First:  p.position(seen[key]),
+					Second: p.position(keyStart),
+				}
+			case DuplicateCollect:
+				if collected[key] {
+					obj[key] = append(existing.([]interface{}), value)
+				} else {
+					if collected == nil {
+						collected = make(map[string]bool)
+					}
+					collected[key] = true
+					obj[key] = []interface{}{existing, value}
+				}
+			default:
+				obj[key] = value
+			}
+		} else {
+			obj[key] = value
+			if p.opts.DuplicateKeys == DuplicateError {
+				if seen == nil {
+					seen = make(map[string]int)
+				}
+				seen[key] = keyStart
+			}
+		}
+
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.unexpected()
+		}
+		switch p.data[p.pos] {
+		case ',':
+			n.addMember(member, p.pos)
+			p.pos++
+			lead = p.pos
+			if err := p.skipSpace(); err != nil {
+				return nil, err
+			}
+			if p.trailingComma('}') {
+				p.closeNode(n, obj, lead, true)
+				return obj, nil
+			}
+		case '}':
+			n.addMember(member, member.trail)
+			p.closeNode(n, obj, member.trail, false)
+			return obj, nil
+		default:
+			return nil, p.unexpected()
+		}
+	}
+}
+
+func (p *parser) parseArray() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	n := p.newNode()
+	p.pos++ // '['
+	arr := make([]interface{}, 0)
+
+	lead := p.pos
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.closeNode(n, arr, lead, false)
+		return arr, nil
+	}
+	for {
+		if p.opts.MaxArrayLength > 0 && len(arr) == p.opts.MaxArrayLength {
+			return nil, p.limitError(LimitArrayLength, p.opts.MaxArrayLength, p.pos)
+		}
+		start := p.pos
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+		member := cstMember{lead: lead, start: start, keyEnd: start, node: p.node, trail: p.pos}
+
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.unexpected()
+		}
+		switch p.data[p.pos] {
+		case ',':
+			n.addMember(member, p.pos)
+			p.pos++
+			lead = p.pos
+			if err := p.skipSpace(); err != nil {
+				return nil, err
+			}
+			if p.trailingComma(']') {
+				p.closeNode(n, arr, lead, true)
+				return arr, nil
+			}
+		case ']':
+			n.addMember(member, member.trail)
+			p.closeNode(n, arr, member.trail, false)
+			return arr, nil
+		default:
+			return nil, p.unexpected()
+		}
+	}
+}
+
+// parseKey parses an object key, which JSON5 also allows to be a
+// single-quoted string or an ECMAScript identifier
+func (p *parser) parseKey() (string, error) {
+	c := p.data[p.pos]
+	if c == '"' || (c == '\'' && p.opts.Syntax == SyntaxJSON5) {
+		return p.parseString()
+	}
+	if p.opts.Syntax != SyntaxJSON5 {
+		return "", p.unexpected()
+	}
+
+	start := p.pos
+	var buf []byte
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if r == '\\' {
+			if p.pos+1 >= len(p.data) || p.data[p.pos+1] != 'u' {
+				return "", p.errorf("invalid escape in identifier")
+			}
+			p.pos += 2
+			var ok bool
+			if r, ok = p.readHex4(); !ok {
+				return "", p.errorf("invalid unicode escape in identifier")
+			}
+			size = 0
+		}
+		if !isIdentifierRune(r, len(buf) == 0) {
+			if size == 0 {
+				return "", p.errorf("invalid escaped character in identifier")
+			}
+			break
+		}
+		buf = utf8.AppendRune(buf, r)
+		p.pos += size
+	}
+	if len(buf) == 0 {
+		return "", p.unexpected()
+	}
+	if max := p.opts.MaxStringLength; max > 0 && len(buf) > max {
+		return "", p.limitError(LimitStringLength, max, start)
+	}
+	return string(buf), nil
+}
+
+func isIdentifierRune(r rune, first bool) bool {
+	switch {
+	case r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r):
+		return true
+	case first:
+		return false
+	case r == '\u200c' || r == '\u200d':
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+func (p *parser) parseString() (string, error) {
+	quote := p.pos
+	delim := p.data[p.pos]
+	p.pos++ // opening quote
+	start := p.pos
+	max := p.opts.MaxStringLength
+
+	// Fast path: no escapes and plain ASCII or valid UTF-8
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == delim {
+			s := p.data[start:p.pos]
+			if utf8.Valid(s) {
+				if max > 0 && len(s) > max {
+					return "", p.limitError(LimitStringLength, max, quote)
+				}
+				p.pos++
+				return string(s), nil
+			}
+			break
+		}
+		if c == '\\' || c < 0x20 {
+			break
+		}
+		p.pos++
+	}
+
+	buf := make([]byte, 0, p.pos-start+16)
+	buf = append(buf, p.data[start:p.pos]...)
+	if !utf8.Valid(buf) {
+		buf = []byte(string([]rune(string(buf))))
+	}
+	for {
+		if max > 0 && len(buf) > max {
+			return "", p.limitError(LimitStringLength, max, quote)
+		}
+		if p.pos >= len(p.data) {
+			return "", p.errorf("unexpected end of JSON input")
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == delim:
+			p.pos++
+			return string(buf), nil
+		case c == '\\':
+			var err error
+			buf, err = p.parseEscape(buf)
+			if err != nil {
+				return "", err
+			}
+		case c < 0x20 && (p.opts.Syntax != SyntaxJSON5 || c == '\n' || c == '\r'):
+			return "", p.errorf("invalid character %s in string literal", quoteChar(c))
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			p.pos++
+		default:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			buf = utf8.AppendRune(buf, r)
+			p.pos += size
+		}
+	}
+}
+
+// parseEscape decodes the escape sequence at p.pos and appends it to buf
+func (p *parser) parseEscape(buf []byte) ([]byte, error) {
+	p.pos++ // backslash
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("unexpected end of JSON input")
+	}
+	c := p.data[p.pos]
+	p.pos++
+	switch c {
+	case '"', '\\', '/':
+		return append(buf, c), nil
+	case 'b':
+		return append(buf, '\b'), nil
+	case 'f':
+		return append(buf, '\f'), nil
+	case 'n':
+		return append(buf, '\n'), nil
+	case 'r':
+		return append(buf, '\r'), nil
+	case 't':
+		return append(buf, '\t'), nil
+	case 'u':
+		r, ok := p.readHex4()
+		if !ok {
+			return nil, p.errorf("invalid unicode escape in string literal")
+		}
+		if utf16.IsSurrogate(r) {
+			r2 := utf8.RuneError
+			if p.pos+1 < len(p.data) && p.data[p.pos] == '\\' && p.data[p.pos+1] == 'u' {
+				save := p.pos
+				p.pos += 2
+				if next, ok := p.readHex4(); ok {
+					r2 = utf16.DecodeRune(r, next)
+				}
+				if r2 == utf8.RuneError {
+					p.pos = save
+				}
+			}
+			r = r2
+		}
+		return utf8.AppendRune(buf, r), nil
+	}
+	if p.opts.Syntax == SyntaxJSON5 {
+		return p.parseEscape5(buf, c)
+	}
+	return nil, p.errorAt(p.pos-1, "invalid character %s in string escape code", quoteChar(c))
+}
+
+// parseEscape5 handles the escapes JSON5 adds on top of JSON, including
+// line continuations; c is the byte after the backslash
+func (p *parser) parseEscape5(buf []byte, c byte) ([]byte, error) {
+	switch {
+	case c == '\'':
+		return append(buf, c), nil
+	case c == 'v':
+		return append(buf, '\v'), nil
+	case c == '0':
+		if p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			return nil, p.errorf("invalid octal escape in string literal")
+		}
+		return append(buf, 0), nil
+	case c == 'x':
+		if p.pos+2 > len(p.data) {
+			return nil, p.errorf("invalid hex escape in string literal")
+		}
+		n, err := strconv.ParseUint(string(p.data[p.pos:p.pos+2]), 16, 8)
+		if err != nil {
+			return nil, p.errorf("invalid hex escape in string literal")
+		}
+		p.pos += 2
+		return utf8.AppendRune(buf, rune(n)), nil
+	case c == '\n':
+		return buf, nil
+	case c == '\r':
+		if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+			p.pos++
+		}
+		return buf, nil
+	case c >= '1' && c <= '9':
+		return nil, p.errorAt(p.pos-1, "invalid character %s in string escape code", quoteChar(c))
+	}
+
+	// Any other character, including U+2028 and U+2029 line continuations,
+	// escapes to itself
+	p.pos--
+	r, size := utf8.DecodeRune(p.data[p.pos:])
+	p.pos += size
+	if r == '\u2028' || r == '\u2029' {
+		return buf, nil
+	}
+	return utf8.AppendRune(buf, r), nil
+}
+
+func (p *parser) readHex4() (rune, bool) {
+	if p.pos+4 > len(p.data) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range p.data[p.pos : p.pos+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r*16 + rune(c)
+	}
+	p.pos += 4
+	return r, true
+}
+
+func (p *parser) parseNumber() (interface{}, error) {
+	if p.opts.Syntax == SyntaxJSON5 {
+		return p.parseNumber5()
+	}
+	start := p.pos
+	if p.data[p.pos] == '-' {
+		p.pos++
+	}
+	if p.pos >= len(p.data) {
+		return nil, p.unexpected()
+	}
+	switch c := p.data[p.pos]; {
+	case c == '0':
+		p.pos++
+	case c >= '1' && c <= '9':
+		p.skipDigits()
+	default:
+		return nil, p.errorf("invalid character %s in numeric literal", quoteChar(c))
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		if !p.skipDigits() {
+			return nil, p.digitError()
+		}
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return nil, p.digitError()
+		}
+	}
+
+	text := string(p.data[start:p.pos])
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, p.errorAt(start, "number %s out of range", text)
+	}
+	return f, nil
+}
+
+// parseNumber5 parses a JSON5 number: an optional sign followed by
+// Infinity, NaN, a hexadecimal integer or a decimal that may start or end
+// with a decimal point. Infinity and NaN become ±Inf and NaN float64s.
+func (p *parser) parseNumber5() (interface{}, error) {
+	start := p.pos
+	sign := 1.0
+	if c := p.data[p.pos]; c == '+' || c == '-' {
+		if c == '-' {
+			sign = -1
+		}
+		p.pos++
+	}
+	rest := p.data[p.pos:]
+	switch {
+	case len(rest) > 0 && rest[0] == 'I':
+		return sign * math.Inf(1), p.parseLiteral("Infinity")
+	case len(rest) > 0 && rest[0] == 'N':
+		return math.NaN(), p.parseLiteral("NaN")
+	case len(rest) > 1 && rest[0] == '0' && (rest[1] == 'x' || rest[1] == 'X'):
+		p.pos += 2
+		digits := p.pos
+		for p.pos < len(p.data) && isHexDigit(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == digits {
+			return nil, p.digitError()
+		}
+		n, _ := new(big.Int).SetString(string(p.data[digits:p.pos]), 16)
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return sign * f, nil
+	}
+
+	intStart := p.pos
+	if p.pos < len(p.data) && p.data[p.pos] == '0' {
+		p.pos++
+		if p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			return nil, p.errorf("invalid leading zero in numeric literal")
+		}
+	} else {
+		p.skipDigits()
+	}
+	hasInt := p.pos > intStart
+	hasFrac := false
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		hasFrac = p.skipDigits()
+	}
+	if !hasInt && !hasFrac {
+		return nil, p.digitError()
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return nil, p.digitError()
+		}
+	}
+
+	text := strings.TrimPrefix(string(p.data[start:p.pos]), "+")
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, p.errorAt(start, "number %s out of range", text)
+	}
+	return f, nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func (p *parser) skipDigits() bool {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *parser) digitError() error {
+	if p.pos >= len(p.data) {
+		return p.unexpected()
+	}
+	return p.errorf("invalid character %s in numeric literal", quoteChar(p.data[p.pos]))
+}
@@ -0,0 +1,290 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseMatchesEncodingJSON(t *testing.T) {
+	inputs := []string{
+		`{"name": "John", "age": 30, "active": true, "tags": ["a", "b"], "none": null}`,
+		`[1, -2.5, 3e10, 0, -0, 1E-3]`,
+		`"esc \"quote\" \\ \/ \b\f\n\r\t é 😀"`,
+		`"lone \ud800 surrogate"`,
+		"\"invalid \xff utf8\"",
+		`  {"nested": {"deep": [[], {}]}}  `,
+	}
+
+	for _, input := range inputs {
+		var want interface{}
+		if err := json.Unmarshal([]byte(input), &want); err != nil {
+			t.Fatalf("encoding/json rejected %q: %v", input, err)
+		}
+		got, err := parse([]byte(input))
+		if err != nil {
+			t.Fatalf("parse(%q) failed: %v", input, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parse(%q) = %#v, want %#v", input, got, want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	inputs := []string{
+		``,
+		`{"name": "John", "age":}`,
+		`[1, 2,]`,
+		`{"a": 1,}`,
+		`01`,
+		`1.`,
+		`-`,
+		`"unterminated`,
+		"\"control \x01\"",
+		`"bad \x escape"`,
+		`{"a" 1}`,
+		`tru`,
+		`1 2`,
+		`1e400`,
+	}
+
+	for _, input := range inputs {
+		if _, err := parse([]byte(input)); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	_, err := Loads("{\n  \"a\": 1,\n  \"b\": x\n}", WithDuplicateKeys(DuplicateKeepLast))
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected *SyntaxError, got %v", err)
+	}
+	if syntaxErr.Pos.Line != 3 || syntaxErr.Pos.Column != 8 {
+		t.Errorf("Expected line 3, column 8, got %s", syntaxErr.Pos)
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	input := `{"a": 1, "b": 2, "a": 3}`
+
+	jv, err := Loads(input, WithDuplicateKeys(DuplicateKeepLast))
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	if jv.Get("a").AsInt() != 3 {
+		t.Errorf("Keep-last expected 3, got %d", jv.Get("a").AsInt())
+	}
+
+	jv, err = Loads(input, WithDuplicateKeys(DuplicateKeepFirst))
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	if jv.Get("a").AsInt() != 1 {
+		t.Errorf("Keep-first expected 1, got %d", jv.Get("a").AsInt())
+	}
+
+	jv, err = Load([]byte(`{"a": [0], "a": 1, "a": 2}`), WithDuplicateKeys(DuplicateCollect))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := []interface{}{[]interface{}{0.0}, 1.0, 2.0}
+	if !reflect.DeepEqual(jv.Get("a").Raw(), want) {
+		t.Errorf("Collect expected %v, got %v", want, jv.Get("a").Raw())
+	}
+}
+
+func TestDuplicateKeyError(t *testing.T) {
+	input := "{\n  \"a\": 1,\n  \"nested\": {\"x\": 1, \"x\": 2}\n}"
+	_, err := Loads(input, WithDuplicateKeys(DuplicateError))
+
+	var dupErr *DuplicateKeyError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("Expected *DuplicateKeyError, got %v", err)
+	}
+	if dupErr.Key != "x" {
+		t.Errorf("Expected key 'x', got %q", dupErr.Key)
+	}
+	if dupErr.First != (Position{Offset: 25, Line: 3, Column: 14}) {
+		t.Errorf("Unexpected first position %+v", dupErr.First)
+	}
+	if dupErr.Second != (Position{Offset: 33, Line: 3, Column: 22}) {
+		t.Errorf("Unexpected second position %+v", dupErr.Second)
+	}
+
+	// The same key in sibling objects is not a duplicate
+	if _, err := Loads(`[{"a": 1}, {"a": 2}]`, WithDuplicateKeys(DuplicateError)); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opt   ParseOption
+		limit Limit
+		pos   Position
+	}{
+		{"depth", `{"a": [[1]]}`, WithMaxDepth(2), LimitDepth, Position{Offset: 7, Line: 1, Column: 8}},
+		{"bytes", `[1, 2, 3]`, WithMaxBytes(4), LimitBytes, Position{Offset: 4, Line: 1, Column: 5}},
+		{"string", `["ok", "too long"]`, WithMaxStringLength(4), LimitStringLength, Position{Offset: 7, Line: 1, Column: 8}},
+		{"escaped string", `"ééé"`, WithMaxStringLength(4), LimitStringLength, Position{Offset: 0, Line: 1, Column: 1}},
+		{"key", `{"long key": 1}`, WithMaxStringLength(4), LimitStringLength, Position{Offset: 1, Line: 1, Column: 2}},
+		{"object keys", "{\"a\": 1,\n \"b\": 2,\n \"c\": 3}", WithMaxObjectKeys(2), LimitObjectKeys, Position{Offset: 19, Line: 3, Column: 2}},
+		{"array length", `[[1, 2], [1, 2, 3]]`, WithMaxArrayLength(2), LimitArrayLength, Position{Offset: 16, Line: 1, Column: 17}},
+	}
+
+	for _, test := range tests {
+		_, err := Loads(test.input, test.opt)
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) {
+			t.Errorf("%s: expected *LimitError, got %v", test.name, err)
+			continue
+		}
+		if limitErr.Limit != test.limit {
+			t.Errorf("%s: expected %s, got %s", test.name, test.limit, limitErr.Limit)
+		}
+		if limitErr.Pos != test.pos {
+			t.Errorf("%s: expected position %+v, got %+v", test.name, test.pos, limitErr.Pos)
+		}
+	}
+}
+
+func TestParseWithinLimits(t *testing.T) {
+	opts := ParseOptions{
+		MaxDepth:        2,
+		MaxBytes:        64,
+		MaxStringLength: 5,
+		MaxObjectKeys:   2,
+		MaxArrayLength:  3,
+	}
+	jv, err := Loads(`{"name": "Alice", "tags": [1, 2, 3]}`, WithParseOptions(opts))
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	if jv.Path("tags.2").AsInt() != 3 {
+		t.Error("Expected value within limits to parse")
+	}
+
+	// Closed siblings give their depth back in every mode
+	siblings := `[[1], {"a": [2]}, [[3]], [4]]`
+	for _, opts := range [][]ParseOption{
+		{WithMaxDepth(3)},
+		{WithMaxDepth(3), WithSyntax(SyntaxJSONC)},
+		{WithMaxDepth(3), WithSyntax(SyntaxJSONC), WithPreserveFormat()},
+	} {
+		if _, err := Loads(siblings, opts...); err != nil {
+			t.Errorf("Unexpected error for siblings within max depth: %v", err)
+		}
+	}
+}
+
+func TestParseJSONC(t *testing.T) {
+	input := `// settings
+{
+	/* editor */
+	"tabSize": 4, // spaces
+	"rulers": [80, 120,],
+	"path": "a//b/*c*/",
+}
+`
+	jv, err := Loads(input, WithSyntax(SyntaxJSONC))
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	want := map[string]interface{}{
+		"tabSize": 4.0,
+		"rulers":  []interface{}{80.0, 120.0},
+		"path":    "a//b/*c*/",
+	}
+	if !reflect.DeepEqual(jv.Raw(), want) {
+		t.Errorf("Expected %v, got %v", want, jv.Raw())
+	}
+
+	// JSONC does not enable the JSON5 extensions
+	for _, input := range []string{`{a: 1}`, `['x']`, `[0x10]`, `[,]`, `[1,,]`, `/* open`} {
+		if _, err := Loads(input, WithSyntax(SyntaxJSONC)); err == nil {
+			t.Errorf("Expected JSONC error for %q", input)
+		}
+	}
+
+	// An unterminated block comment after a value is not silently dropped
+	for _, syntax := range []Syntax{SyntaxJSONC, SyntaxJSON5} {
+		_, err := Loads("{\"a\": 1}\n  /* oops", WithSyntax(syntax))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Msg != "unterminated comment" ||
+			syntaxErr.Pos.Line != 2 || syntaxErr.Pos.Column != 3 {
+			t.Errorf("Expected unterminated comment at line 2, column 3, got %v", err)
+		}
+	}
+
+	// Strict JSON still rejects comments and trailing commas
+	if _, err := Loads(`[1, 2,]`, WithSyntax(SyntaxJSON)); err == nil {
+		t.Error("Expected error for trailing comma in strict JSON")
+	}
+}
+
+func TestParseJSON5(t *testing.T) {
+	input := `{
+  // comments
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+  $id_1: '\x41B\'\0',
+  infinity: -Infinity,
+}`
+	jv, err := Loads(input, WithSyntax(SyntaxJSON5))
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+
+	checks := map[string]interface{}{
+		"unquoted":            "and you can quote me on that",
+		"singleQuotes":        `I can use "double quotes" here`,
+		"lineBreaks":          `Look, Mom! No \n's!`,
+		"hexadecimal":         912559.0,
+		"leadingDecimalPoint": 0.8675309,
+		"andTrailing":         8675309.0,
+		"positiveSign":        1.0,
+		"backwardsCompatible": "with JSON",
+		"$id_1":               "AB'\x00",
+	}
+	for key, want := range checks {
+		if got := jv.Get(key).Raw(); got != want {
+			t.Errorf("%s: expected %#v, got %#v", key, want, got)
+		}
+	}
+	if !math.IsInf(jv.Get("infinity").AsFloat(), -1) {
+		t.Errorf("Expected -Infinity, got %v", jv.Get("infinity").Raw())
+	}
+	if jv.Q("andIn", 0).AsString() != "arrays" {
+		t.Error("Expected trailing comma in array to be accepted")
+	}
+
+	nan, err := Loads(`NaN`, WithSyntax(SyntaxJSON5))
+	if err != nil || !math.IsNaN(nan.AsFloat()) {
+		t.Errorf("Expected NaN, got %v (%v)", nan, err)
+	}
+	// JSON has no infinities or NaN, so they load but do not dump
+	if _, err := jv.Dumps(); err == nil {
+		t.Error("Expected Dumps to reject -Infinity")
+	}
+
+	for _, input := range []string{`{1a: 1}`, `[01]`, `[.]`, `['\1']`, `[0x]`, `"a
+b"`} {
+		if _, err := Loads(input, WithSyntax(SyntaxJSON5)); err == nil {
+			t.Errorf("Expected JSON5 error for %q", input)
+		}
+	}
+}
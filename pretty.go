@@ -0,0 +1,246 @@
+package easyjson
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// PrettyOptions configures DumpsPretty
+type PrettyOptions struct {
+	Indent string // one level of indentation, two spaces when empty
+	Width  int    // target line width, 80 when zero
+
+	// AlignValues pads the keys of objects that span several lines so
+	// their values start in the same column
+	AlignValues bool
+
+	// MaxStringLength truncates longer strings, in characters, ending them
+	// with an elision marker. Zero keeps strings whole.
+	MaxStringLength int
+
+	// MaxArrayItems shows at most this many elements of an array, followed
+	// by a marker counting the rest. Zero shows every element.
+	MaxArrayItems int
+}
+
+// DumpsPretty converts the JSONValue to a human-friendly string. Objects
+// and arrays that fit within the line width stay on one line, arrays of
+// scalars that do not fit are wrapped across as few lines as possible,
+// and everything else gets one member per line like DumpsIndent.
+//
+// With MaxStringLength or MaxArrayItems set the output is meant for logs:
+// it is still valid JSON, but elided content is replaced by marker strings.
+func (jv *JSONValue) DumpsPretty(opts PrettyOptions) (string, error) {
+	if opts.Indent == "" {
+		opts.Indent = "  "
+	}
+	if opts.Width <= 0 {
+		opts.Width = 80
+	}
+	p := &prettyPrinter{opts: opts, enc: encodeOptions{floatPrec: -1}}
+	value, err := p.prepare(jv.data)
+	if err != nil {
+		return "", err
+	}
+	p.write(value, 0, 0, 0)
+	return string(p.buf), nil
+}
+
+type prettyPrinter struct {
+	opts PrettyOptions
+	enc  encodeOptions
+	buf  []byte
+}
+
+// prettyNode is a value ready for layout: scalars are already encoded
+// and containers know the width of their single-line form
+type prettyNode struct {
+	text    string // encoded scalar, or single-line form of a container
+	object  bool
+	keys    []string // encoded keys of an object
+	members []*prettyNode
+}
+
+func (n *prettyNode) container() bool {
+	return n.members != nil
+}
+
+// prepare encodes scalars, applies truncation and computes the
+// single-line form of every container
+func (p *prettyPrinter) prepare(value interface{}) (*prettyNode, error) {
+	switch v := value.(type) {
+	case *JSONValue:
+		if v == nil {
+			return &prettyNode{text: "null"}, nil
+		}
+		return p.prepare(v.data)
+	case string:
+		if max := p.opts.MaxStringLength; max > 0 && utf8.RuneCountInString(v) > max {
+			runes := []rune(v)
+			v = fmt.Sprintf("%s…(+%d chars)", string(runes[:max]), len(runes)-max)
+		}
+		return &prettyNode{text: string(p.enc.appendString(nil, v))}, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		n := &prettyNode{object: true, members: make([]*prettyNode, 0, len(keys))}
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			member, err := p.prepare(v[k])
+			if err != nil {
+				return nil, err
+			}
+			key := string(p.enc.appendString(nil, k))
+			n.keys = append(n.keys, key)
+			n.members = append(n.members, member)
+			parts[i] = key + ": " + member.text
+		}
+		n.text = "{" + strings.Join(parts, ", ") + "}"
+		return n, nil
+	case []interface{}:
+		items := v
+		elided := 0
+		if max := p.opts.MaxArrayItems; max > 0 && len(items) > max {
+			items, elided = items[:max], len(items)-max
+		}
+		n := &prettyNode{members: make([]*prettyNode, 0, len(items)+1)}
+		parts := make([]string, 0, len(items)+1)
+		for _, item := range items {
+			member, err := p.prepare(item)
+			if err != nil {
+				return nil, err
+			}
+			n.members = append(n.members, member)
+			parts = append(parts, member.text)
+		}
+		if elided > 0 {
+			marker := &prettyNode{text: fmt.Sprintf(`"…(+%d items)"`, elided)}
+			n.members = append(n.members, marker)
+			parts = append(parts, marker.text)
+		}
+		n.text = "[" + strings.Join(parts, ", ") + "]"
+		return n, nil
+	}
+
+	buf, ok, err := p.enc.appendScalar(nil, value)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &prettyNode{text: string(buf)}, nil
+	}
+	generic, err := genericValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return p.prepare(generic)
+}
+
+// write lays out n starting at column col, leaving room for trail
+// characters (a comma) after it
+func (p *prettyPrinter) write(n *prettyNode, depth, col, trail int) {
+	if !n.container() || len(n.members) == 0 || col+width(n.text)+trail <= p.opts.Width {
+		if n.container() && len(n.members) == 0 {
+			if n.object {
+				p.buf = append(p.buf, "{}"...)
+			} else {
+				p.buf = append(p.buf, "[]"...)
+			}
+			return
+		}
+		p.buf = append(p.buf, n.text...)
+		return
+	}
+
+	inner := strings.Repeat(p.opts.Indent, depth+1)
+	if n.object {
+		keyWidth := 0
+		if p.opts.AlignValues {
+			for _, key := range n.keys {
+				keyWidth = max(keyWidth, width(key))
+			}
+		}
+		p.buf = append(p.buf, '{')
+		for i, member := range n.members {
+			p.buf = append(p.buf, '\n')
+			p.buf = append(p.buf, inner...)
+			p.buf = append(p.buf, n.keys[i]...)
+			p.buf = append(p.buf, ':', ' ')
+			pad := 0
+			if keyWidth > 0 {
+				pad = keyWidth - width(n.keys[i])
+				p.buf = append(p.buf, strings.Repeat(" ", pad)...)
+			}
+			p.write(member, depth+1, width(inner)+width(n.keys[i])+2+pad, commaAfter(i, len(n.members)))
+			if i < len(n.members)-1 {
+				p.buf = append(p.buf, ',')
+			}
+		}
+		p.closeLine(depth, '}')
+		return
+	}
+
+	p.buf = append(p.buf, '[')
+	if allScalars(n.members) {
+		// Fill each line with as many elements as fit
+		line := 0
+		for i, member := range n.members {
+			need := width(member.text) + commaAfter(i, len(n.members))
+			if i == 0 || line+1+need > p.opts.Width {
+				p.buf = append(p.buf, '\n')
+				p.buf = append(p.buf, inner...)
+				line = width(inner)
+			} else {
+				p.buf = append(p.buf, ' ')
+				line++
+			}
+			p.buf = append(p.buf, member.text...)
+			if i < len(n.members)-1 {
+				p.buf = append(p.buf, ',')
+			}
+			line += need
+		}
+	} else {
+		for i, member := range n.members {
+			p.buf = append(p.buf, '\n')
+			p.buf = append(p.buf, inner...)
+			p.write(member, depth+1, width(inner), commaAfter(i, len(n.members)))
+			if i < len(n.members)-1 {
+				p.buf = append(p.buf, ',')
+			}
+		}
+	}
+	p.closeLine(depth, ']')
+}
+
+func (p *prettyPrinter) closeLine(depth int, closing byte) {
+	p.buf = append(p.buf, '\n')
+	p.buf = append(p.buf, strings.Repeat(p.opts.Indent, depth)...)
+	p.buf = append(p.buf, closing)
+}
+
+func allScalars(nodes []*prettyNode) bool {
+	for _, n := range nodes {
+		if n.container() {
+			return false
+		}
+	}
+	return true
+}
+
+func commaAfter(i, n int) int {
+	if i < n-1 {
+		return 1
+	}
+	return 0
+}
+
+// width is the display width of s, counting characters rather than bytes
+func width(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,92 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func prettyString(t *testing.T, src string, opts PrettyOptions) string {
+	t.Helper()
+	jv, err := Loads(src)
+	if err != nil {
+		t.Fatalf("Loads failed: %v", err)
+	}
+	out, err := jv.DumpsPretty(opts)
+	if err != nil {
+		t.Fatalf("DumpsPretty failed: %v", err)
+	}
+	return out
+}
+
+func TestDumpsPrettyLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts PrettyOptions
+		want string
+	}{
+		{"scalar", `"x<y"`, PrettyOptions{}, `"x<y"`},
+		{"empty", `{"a": {}, "b": []}`, PrettyOptions{}, `{"a": {}, "b": []}`},
+		{"fits", `{"b": [1, 2, 3], "a": {"x": true}}`, PrettyOptions{}, `{"a": {"x": true}, "b": [1, 2, 3]}`},
+		{"breaks", `{"name": "example", "tags": ["a", "b"]}`, PrettyOptions{Width: 20},
+			"{\n  \"name\": \"example\",\n  \"tags\": [\"a\", \"b\"]\n}"},
+		{"fills", `[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]`, PrettyOptions{Width: 12},
+			"[\n  1, 2, 3,\n  4, 5, 6,\n  7, 8, 9,\n  10\n]"},
+		{"nested", `[[1, 2], {"a": 1}]`, PrettyOptions{Width: 10, Indent: "\t"},
+			"[\n\t[1, 2],\n\t{\"a\": 1}\n]"},
+		{"comma fits", `{"k": [1, 2], "z": 0}`, PrettyOptions{Width: 14},
+			"{\n  \"k\": [1, 2],\n  \"z\": 0\n}"},
+		{"comma overflows", `{"k": [1, 2], "z": 0}`, PrettyOptions{Width: 13},
+			"{\n  \"k\": [\n    1, 2\n  ],\n  \"z\": 0\n}"},
+		{"align", `{"a": 1, "long": 2, "mid": [3]}`, PrettyOptions{Width: 14, AlignValues: true},
+			"{\n  \"a\":    1,\n  \"long\": 2,\n  \"mid\":  [3]\n}"},
+		{"truncate string", `["abcdefgh", "short"]`, PrettyOptions{MaxStringLength: 5},
+			`["abcde…(+3 chars)", "short"]`},
+		{"truncate array", `[1, 2, 3, 4, 5]`, PrettyOptions{MaxArrayItems: 2},
+			`[1, 2, "…(+3 items)"]`},
+	}
+
+	for _, tt := range tests {
+		if got := prettyString(t, tt.src, tt.opts); got != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDumpsPrettyRoundTrip(t *testing.T) {
+	src := `{"users": [{"id": 1, "name": "Ann", "roles": ["admin", "dev"]}, {"id": 2, "name": "Bob", "roles": []}],
+		"matrix": [[1, 2, 3], [4, 5, 6]], "note": "line\nbreak", "values": [` + strings.Repeat("1.5, ", 200) + `0]}`
+
+	for _, w := range []int{1, 30, 80, 1000} {
+		out := prettyString(t, src, PrettyOptions{Width: w})
+		var got, want interface{}
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("width %d: invalid output: %v\n%s", w, err, out)
+		}
+		json.Unmarshal([]byte(src), &want)
+		a, _ := json.Marshal(got)
+		b, _ := json.Marshal(want)
+		if string(a) != string(b) {
+			t.Errorf("width %d: round trip changed the document", w)
+		}
+		if w >= 30 {
+			for _, line := range strings.Split(out, "\n") {
+				if width(line) > w {
+					t.Errorf("width %d: line too long: %q", w, line)
+				}
+			}
+		}
+	}
+}
+
+func TestDumpsPrettyGoValues(t *testing.T) {
+	jv := New(map[string]interface{}{"n": int64(3), "s": struct{ A int }{1}})
+	out, err := jv.DumpsPretty(PrettyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != `{"n": 3, "s": {"A": 1}}` {
+		t.Errorf("Unexpected output %s", out)
+	}
+}
@@ -0,0 +1,211 @@
+package easyjson
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactRule selects values to redact and what to do with them. Rules are
+// created with RedactKey, RedactGlob, RedactRegex or RedactPath and replace
+// matching values with "***" unless another action is chosen.
+type RedactRule struct {
+	match       func(keys []string, key string, isKey bool) bool
+	action      redactAction
+	replacement interface{}
+	keep        int
+}
+
+type redactAction int
+
+const (
+	redactReplace redactAction = iota
+	redactRemove
+	redactHash
+	redactKeepLast
+)
+
+// RedactKey matches object members whose key is one of names
+func RedactKey(names ...string) RedactRule {
+	return newRedactRule(func(_ []string, key string, isKey bool) bool {
+		if !isKey {
+			return false
+		}
+		for _, name := range names {
+			if key == name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RedactGlob matches object members whose key matches a path.Match
+// pattern such as "*_token"
+func RedactGlob(pattern string) RedactRule {
+	return newRedactRule(func(_ []string, key string, isKey bool) bool {
+		ok, err := path.Match(pattern, key)
+		return isKey && err == nil && ok
+	})
+}
+
+// RedactRegex matches object members whose key matches re
+func RedactRegex(re *regexp.Regexp) RedactRule {
+	return newRedactRule(func(_ []string, key string, isKey bool) bool {
+		return isKey && re.MatchString(key)
+	})
+}
+
+// RedactPath matches values by their dot-separated path, as used by Path.
+// Each segment of pattern is a path.Match pattern, so "*" matches any one
+// key or array index, and a "**" segment matches any number of segments:
+// "users.*.ssn" or "**.password".
+func RedactPath(pattern string) RedactRule {
+	segments := strings.Split(pattern, ".")
+	return newRedactRule(func(keys []string, _ string, _ bool) bool {
+		return matchSegments(segments, keys)
+	})
+}
+
+func newRedactRule(match func(keys []string, key string, isKey bool) bool) RedactRule {
+	return RedactRule{match: match, replacement: "***"}
+}
+
+// Remove makes the rule delete matching object members and array elements
+func (r RedactRule) Remove() RedactRule {
+	r.action = redactRemove
+	return r
+}
+
+// Replace makes the rule replace matching values with replacement
+func (r RedactRule) Replace(replacement interface{}) RedactRule {
+	r.action = redactReplace
+	r.replacement = replacement
+	return r
+}
+
+// Hash makes the rule replace matching values with "sha256:" followed by
+// the hex SHA-256 of the string, or of the compact JSON of other values,
+// so equal secrets can still be correlated
+func (r RedactRule) Hash() RedactRule {
+	r.action = redactHash
+	return r
+}
+
+// KeepLast makes the rule mask all but the last n characters of matching
+// values with '*', as in "************4242". Values of n characters or
+// fewer are masked completely and containers become "***".
+func (r RedactRule) KeepLast(n int) RedactRule {
+	r.action = redactKeepLast
+	r.keep = n
+	return r
+}
+
+func (r *RedactRule) apply(value interface{}) interface{} {
+	switch r.action {
+	case redactHash:
+		sum := sha256.Sum256([]byte(redactText(value)))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	case redactKeepLast:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return "***"
+		}
+		runes := []rune(redactText(value))
+		if len(runes) <= r.keep {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-r.keep) + string(runes[len(runes)-r.keep:])
+	}
+	return r.replacement
+}
+
+// redactText is the text a hash or mask is computed from
+func redactText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil || !utf8.Valid(data) {
+		return ""
+	}
+	return string(data)
+}
+
+// Redact returns a copy of the value with every value matched by a rule
+// redacted, searching nested objects and arrays. The first matching rule
+// wins, and values inside a redacted container are not visited.
+func (jv *JSONValue) Redact(rules ...RedactRule) *JSONValue {
+	return &JSONValue{data: redactValue(jv.data, nil, rules)}
+}
+
+func redactValue(value interface{}, path []string, rules []RedactRule) interface{} {
+	if jv, ok := value.(*JSONValue); ok && jv != nil {
+		value = jv.data
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			child := append(path[:len(path):len(path)], k)
+			if rule := matchRule(rules, child, k, true); rule != nil {
+				if rule.action != redactRemove {
+					out[k] = rule.apply(item)
+				}
+				continue
+			}
+			out[k] = redactValue(item, child, rules)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for i, item := range v {
+			child := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if rule := matchRule(rules, child, "", false); rule != nil {
+				if rule.action != redactRemove {
+					out = append(out, rule.apply(item))
+				}
+				continue
+			}
+			out = append(out, redactValue(item, child, rules))
+		}
+		return out
+	}
+	return value
+}
+
+func matchRule(rules []RedactRule, path []string, key string, isKey bool) *RedactRule {
+	for i := range rules {
+		if rules[i].match != nil && rules[i].match(path, key, isKey) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// matchSegments matches a path against pattern segments, where "**"
+// stands for any number of segments
+func matchSegments(pattern, keys []string) bool {
+	if len(pattern) == 0 {
+		return len(keys) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(keys); i++ {
+			if matchSegments(pattern[1:], keys[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(keys) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], keys[0])
+	return err == nil && ok && matchSegments(pattern[1:], keys[1:])
+}
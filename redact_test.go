@@ -0,0 +1,90 @@
+package easyjson
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"testing"
+)
+
+const redactDoc = `{
+	"user": {"name": "Ann", "password": "hunter2", "api_token": "abc", "card": "4111111111114242"},
+	"sessions": [{"id": 1, "refresh_token": "r1"}, {"id": 2, "refresh_token": "r2"}],
+	"users": [{"ssn": "123-45-6789", "pin": 1234}],
+	"audit": {"password": "old"}
+}`
+
+func redactDump(t *testing.T, rules ...RedactRule) string {
+	t.Helper()
+	jv, err := Loads(redactDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := jv.Dumps()
+	out, _ := jv.Redact(rules...).Dumps()
+	if after, _ := jv.Dumps(); after != before {
+		t.Error("Redact modified the original value")
+	}
+	return out
+}
+
+func TestRedactMatchers(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []RedactRule
+		want  string
+	}{
+		{"key", []RedactRule{RedactKey("password", "pin")},
+			`{"audit":{"password":"***"},"sessions":[{"id":1,"refresh_token":"r1"},{"id":2,"refresh_token":"r2"}],"user":{"api_token":"abc","card":"4111111111114242","name":"Ann","password":"***"},"users":[{"pin":"***","ssn":"123-45-6789"}]}`},
+		{"glob", []RedactRule{RedactGlob("*_token")},
+			`{"audit":{"password":"old"},"sessions":[{"id":1,"refresh_token":"***"},{"id":2,"refresh_token":"***"}],"user":{"api_token":"***","card":"4111111111114242","name":"Ann","password":"hunter2"},"users":[{"pin":1234,"ssn":"123-45-6789"}]}`},
+		{"regex", []RedactRule{RedactRegex(regexp.MustCompile(`^(?i)CARD|SSN$`))},
+			`{"audit":{"password":"old"},"sessions":[{"id":1,"refresh_token":"r1"},{"id":2,"refresh_token":"r2"}],"user":{"api_token":"abc","card":"***","name":"Ann","password":"hunter2"},"users":[{"pin":1234,"ssn":"***"}]}`},
+		{"path", []RedactRule{RedactPath("user.password"), RedactPath("users.*.ssn"), RedactPath("sessions.1")},
+			`{"audit":{"password":"old"},"sessions":[{"id":1,"refresh_token":"r1"},"***"],"user":{"api_token":"abc","card":"4111111111114242","name":"Ann","password":"***"},"users":[{"pin":1234,"ssn":"***"}]}`},
+		{"deep path", []RedactRule{RedactPath("**.password")},
+			`{"audit":{"password":"***"},"sessions":[{"id":1,"refresh_token":"r1"},{"id":2,"refresh_token":"r2"}],"user":{"api_token":"abc","card":"4111111111114242","name":"Ann","password":"***"},"users":[{"pin":1234,"ssn":"123-45-6789"}]}`},
+		{"container", []RedactRule{RedactKey("user", "sessions")},
+			`{"audit":{"password":"old"},"sessions":"***","user":"***","users":[{"pin":1234,"ssn":"123-45-6789"}]}`},
+	}
+
+	for _, tt := range tests {
+		if got := redactDump(t, tt.rules...); got != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRedactActions(t *testing.T) {
+	sum := sha256.Sum256([]byte("hunter2"))
+	pinSum := sha256.Sum256([]byte("1234"))
+
+	tests := []struct {
+		name  string
+		rules []RedactRule
+		want  string
+	}{
+		{"remove", []RedactRule{RedactKey("password", "card").Remove(), RedactPath("sessions.0").Remove()},
+			`{"audit":{},"sessions":[{"id":2,"refresh_token":"r2"}],"user":{"api_token":"abc","name":"Ann"},"users":[{"pin":1234,"ssn":"123-45-6789"}]}`},
+		{"replace", []RedactRule{RedactGlob("*_token").Replace(nil)},
+			`{"audit":{"password":"old"},"sessions":[{"id":1,"refresh_token":null},{"id":2,"refresh_token":null}],"user":{"api_token":null,"card":"4111111111114242","name":"Ann","password":"hunter2"},"users":[{"pin":1234,"ssn":"123-45-6789"}]}`},
+		{"hash", []RedactRule{RedactPath("user.password").Hash(), RedactKey("pin").Hash()},
+			`{"audit":{"password":"old"},"sessions":[{"id":1,"refresh_token":"r1"},{"id":2,"refresh_token":"r2"}],"user":{"api_token":"abc","card":"4111111111114242","name":"Ann","password":"sha256:` + hex.EncodeToString(sum[:]) + `"},"users":[{"pin":"sha256:` + hex.EncodeToString(pinSum[:]) + `","ssn":"123-45-6789"}]}`},
+		{"keep last", []RedactRule{RedactKey("card", "ssn", "pin").KeepLast(4), RedactKey("api_token", "user").KeepLast(4)},
+			`{"audit":{"password":"old"},"sessions":[{"id":1,"refresh_token":"r1"},{"id":2,"refresh_token":"r2"}],"user":"***","users":[{"pin":"****","ssn":"*******6789"}]}`},
+		{"first rule wins", []RedactRule{RedactKey("password").Remove(), RedactPath("**").Replace("x")},
+			`{"audit":"x","sessions":"x","user":"x","users":"x"}`},
+	}
+
+	for _, tt := range tests {
+		if got := redactDump(t, tt.rules...); got != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.want, got)
+		}
+	}
+
+	card := New(map[string]interface{}{"card": "4111111111114242", "short": "ab"})
+	out, _ := card.Redact(RedactKey("card", "short").KeepLast(4)).Dumps()
+	if out != `{"card":"************4242","short":"**"}` {
+		t.Errorf("Unexpected masking %s", out)
+	}
+}
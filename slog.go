@@ -0,0 +1,128 @@
+package easyjson
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// LogOptions configures how a JSONValue is converted for log/slog
+type LogOptions struct {
+	// MaxDepth limits how many levels of objects and arrays are expanded.
+	// Deeper containers are logged as a short summary such as "{…3 keys}".
+	// Zero expands everything.
+	MaxDepth int
+
+	// Redact is called for every value with its dot-separated path, as
+	// used by Path. Returning true replaces the value with replacement.
+	Redact func(path string, value interface{}) (replacement interface{}, redact bool)
+}
+
+// LogValue implements slog.LogValuer. Objects become groups so handlers
+// can index their fields, arrays become slices and scalars use the
+// matching slog kind, with integral numbers logged as int64.
+func (jv *JSONValue) LogValue() slog.Value {
+	return jv.LogValuer(LogOptions{}).LogValue()
+}
+
+// LogValuer returns an slog.LogValuer that converts the value with opts
+func (jv *JSONValue) LogValuer(opts LogOptions) slog.LogValuer {
+	return logValuer{jv: jv, opts: opts}
+}
+
+type logValuer struct {
+	jv   *JSONValue
+	opts LogOptions
+}
+
+func (l logValuer) LogValue() slog.Value {
+	if l.jv == nil {
+		return slog.AnyValue(nil)
+	}
+	return l.opts.value(l.jv.data, "", 0)
+}
+
+func (o *LogOptions) value(value interface{}, path string, depth int) slog.Value {
+	if jv, ok := value.(*JSONValue); ok && jv != nil {
+		value = jv.data
+	}
+	if o.Redact != nil {
+		if replacement, ok := o.Redact(path, value); ok {
+			return slog.AnyValue(replacement)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if o.MaxDepth > 0 && depth >= o.MaxDepth {
+			return slog.StringValue(summary(v))
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		attrs := make([]slog.Attr, len(keys))
+		for i, k := range keys {
+			attrs[i] = slog.Attr{Key: k, Value: o.value(v[k], joinPath(path, k), depth+1)}
+		}
+		return slog.GroupValue(attrs...)
+	case []interface{}:
+		if o.MaxDepth > 0 && depth >= o.MaxDepth {
+			return slog.StringValue(summary(v))
+		}
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = o.plain(item, joinPath(path, strconv.Itoa(i)), depth+1)
+		}
+		return slog.AnyValue(items)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return slog.Int64Value(int64(v))
+		}
+		return slog.Float64Value(v)
+	}
+	return slog.AnyValue(value)
+}
+
+// plain converts an array element. Groups cannot appear inside slices, so
+// objects there are logged as maps.
+func (o *LogOptions) plain(value interface{}, path string, depth int) interface{} {
+	if jv, ok := value.(*JSONValue); ok && jv != nil {
+		value = jv.data
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok || o.MaxDepth > 0 && depth >= o.MaxDepth {
+		return o.value(value, path, depth).Any()
+	}
+	if o.Redact != nil {
+		if replacement, ok := o.Redact(path, value); ok {
+			return replacement
+		}
+	}
+	out := make(map[string]interface{}, len(obj))
+	for k, item := range obj {
+		out[k] = o.plain(item, joinPath(path, k), depth+1)
+	}
+	return out
+}
+
+// summary describes a container that is too deep to log
+func summary(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return fmt.Sprintf("{…%d keys}", len(v))
+	case []interface{}:
+		return fmt.Sprintf("[…%d items]", len(v))
+	}
+	return ""
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,77 @@
+package easyjson
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func logLine(t *testing.T, value interface{}) string {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("event", "payload", value)
+	return strings.TrimSpace(buf.String())
+}
+
+func TestLogValue(t *testing.T) {
+	jv, _ := Loads(`{"user": {"id": 7, "name": "Ann", "score": 1.5}, "tags": ["a", {"b": null}], "ok": true}`)
+
+	got := logLine(t, jv)
+	want := `{"msg":"event","payload":{"ok":true,"tags":["a",{"b":null}],"user":{"id":7,"name":"Ann","score":1.5}}}`
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	v := jv.LogValue()
+	if v.Kind() != slog.KindGroup {
+		t.Fatalf("Expected a group, got %v", v.Kind())
+	}
+	user := v.Group()[2].Value.Group()
+	if user[0].Value.Kind() != slog.KindInt64 || user[1].Value.Kind() != slog.KindString || user[2].Value.Kind() != slog.KindFloat64 {
+		t.Errorf("Unexpected kinds %v", user)
+	}
+	if New(nil).LogValue().Any() != nil {
+		t.Error("Expected null to log as nil")
+	}
+}
+
+func TestLogValuerOptions(t *testing.T) {
+	jv, _ := Loads(`{"user": {"password": "hunter2", "roles": ["admin"], "sessions": [{"token": "t1"}]}, "id": 1}`)
+
+	redact := func(path string, value interface{}) (interface{}, bool) {
+		if strings.HasSuffix(path, "password") || strings.HasSuffix(path, "token") {
+			return "[REDACTED]", true
+		}
+		return nil, false
+	}
+
+	got := logLine(t, jv.LogValuer(LogOptions{Redact: redact}))
+	want := `{"msg":"event","payload":{"id":1,"user":{"password":"[REDACTED]","roles":["admin"],"sessions":[{"token":"[REDACTED]"}]}}}`
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	got = logLine(t, jv.LogValuer(LogOptions{MaxDepth: 1}))
+	want = `{"msg":"event","payload":{"id":1,"user":"{…3 keys}"}}`
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	var paths []string
+	jv.LogValuer(LogOptions{Redact: func(path string, value interface{}) (interface{}, bool) {
+		paths = append(paths, path)
+		return nil, false
+	}}).LogValue()
+	if strings.Join(paths, " ") != " id user user.password user.roles user.roles.0 user.sessions user.sessions.0 user.sessions.0.token" {
+		t.Errorf("Unexpected redaction paths %q", paths)
+	}
+}
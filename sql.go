@@ -0,0 +1,60 @@
+package easyjson
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Scan implements sql.Scanner for JSON, jsonb and text columns. SQL NULL
+// scans as a JSON null; use NullJSONValue to tell the two apart.
+func (jv *JSONValue) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*jv = JSONValue{}
+		return nil
+	case []byte:
+		return jv.UnmarshalJSON(v)
+	case string:
+		return jv.UnmarshalJSON([]byte(v))
+	}
+	return fmt.Errorf("cannot scan %T into JSONValue", src)
+}
+
+// Value implements driver.Valuer, storing the compact JSON text
+func (jv JSONValue) Value() (driver.Value, error) {
+	data, err := jv.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// NullJSONValue is a JSONValue that may be SQL NULL, in the style of
+// sql.NullString. Valid is false for NULL columns, while a column holding
+// the JSON text null scans as Valid with a null JSONValue.
+type NullJSONValue struct {
+	JSONValue JSONValue
+	Valid     bool
+}
+
+// Scan implements sql.Scanner
+func (n *NullJSONValue) Scan(src interface{}) error {
+	if src == nil {
+		*n = NullJSONValue{}
+		return nil
+	}
+	if err := n.JSONValue.Scan(src); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements driver.Valuer, returning nil for SQL NULL
+func (n NullJSONValue) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.JSONValue.Value()
+}
@@ -0,0 +1,164 @@
+package easyjson
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDriver is an in-memory database with a single two-column table.
+// INSERT statements append their arguments as a row and any other query
+// returns every row.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows [][]driver.Value
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.rows = append(s.d.rows, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	if strings.HasPrefix(s.query, "BYTES") {
+		// Return text columns as []byte like most drivers do
+		rows := make([][]driver.Value, len(s.d.rows))
+		for i, row := range s.d.rows {
+			rows[i] = make([]driver.Value, len(row))
+			for j, v := range row {
+				if str, ok := v.(string); ok {
+					rows[i][j] = []byte(str)
+				} else {
+					rows[i][j] = v
+				}
+			}
+		}
+		return &fakeRows{rows: rows}, nil
+	}
+	return &fakeRows{rows: append([][]driver.Value(nil), s.d.rows...)}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"doc", "extra"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+var fakeDB = &fakeDriver{}
+
+func init() {
+	sql.Register("easyjson-fake", fakeDB)
+}
+
+func openFakeDB(t *testing.T) *sql.DB {
+	t.Helper()
+	fakeDB.mu.Lock()
+	fakeDB.rows = nil
+	fakeDB.mu.Unlock()
+	db, err := sql.Open("easyjson-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLRoundTrip(t *testing.T) {
+	db := openFakeDB(t)
+	doc, _ := Loads(`{"name": "Ann", "tags": ["a"]}`)
+
+	if _, err := db.Exec("INSERT", doc, NullJSONValue{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT", New(nil), NullJSONValue{JSONValue: *New(1.5), Valid: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{"SELECT", "BYTES"} {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var docs []string
+		var extras []NullJSONValue
+		for rows.Next() {
+			var got JSONValue
+			var extra NullJSONValue
+			if err := rows.Scan(&got, &extra); err != nil {
+				t.Fatalf("%s: %v", query, err)
+			}
+			s, _ := got.Dumps()
+			docs = append(docs, s)
+			extras = append(extras, extra)
+		}
+		rows.Close()
+
+		if len(docs) != 2 || docs[0] != `{"name":"Ann","tags":["a"]}` || docs[1] != "null" {
+			t.Errorf("%s: unexpected documents %v", query, docs)
+		}
+		if extras[0].Valid || !extras[1].Valid || extras[1].JSONValue.AsFloat() != 1.5 {
+			t.Errorf("%s: unexpected nullable values %+v", query, extras)
+		}
+	}
+}
+
+func TestSQLScan(t *testing.T) {
+	var jv JSONValue
+	if err := jv.Scan(nil); err != nil || !jv.IsNull() {
+		t.Errorf("Expected NULL to scan as null, got %v", err)
+	}
+	if err := jv.Scan(42); err == nil {
+		t.Error("Expected error scanning an int")
+	}
+	if err := jv.Scan("{bad"); err == nil {
+		t.Error("Expected error scanning invalid JSON")
+	}
+
+	var n NullJSONValue
+	if err := n.Scan("null"); err != nil || !n.Valid || !n.JSONValue.IsNull() {
+		t.Errorf("Expected JSON null to be valid, got %+v (%v)", n, err)
+	}
+	if err := n.Scan([]byte("[")); err == nil || n.Valid {
+		t.Error("Expected invalid JSON to fail and leave the value invalid")
+	}
+
+	var nilValue *JSONValue
+	if v, err := driver.DefaultParameterConverter.ConvertValue(nilValue); err != nil || v != nil {
+		t.Errorf("Expected nil pointer to convert to NULL, got %v (%v)", v, err)
+	}
+}
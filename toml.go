@@ -0,0 +1,866 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LoadTOML parses a TOML 1.0 document into a JSONValue object. Tables,
+// arrays of tables and inline tables become objects and arrays, and
+// integers and floats become numbers; integers beyond 2^53 lose precision
+// as they do with encoding/json. inf and nan are rejected because JSON
+// cannot represent them. Errors are *SyntaxError values with line numbers.
+//
+// Datetimes become strings in RFC 3339 form, with the date and time
+// separated by "T" and an upper-case "Z":
+//
+//	offset date-time  1979-05-27 07:32:00z      "1979-05-27T07:32:00Z"
+//	local date-time   1979-05-27T07:32:00.5     "1979-05-27T07:32:00.5"
+//	local date        1979-05-27                "1979-05-27"
+//	local time        07:32:00                  "07:32:00"
+func LoadTOML(data []byte) (*JSONValue, error) {
+	p := &tomlParser{data: data, root: newTOMLTable(tomlDefined)}
+	if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		p.pos = 3
+	}
+	current := p.root
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return &JSONValue{data: p.root.data()}, nil
+		}
+		var err error
+		switch p.peek() {
+		case '#', '\r', '\n':
+		case '[':
+			current, err = p.header()
+		default:
+			err = p.keyValue(current)
+		}
+		if err == nil {
+			err = p.endLine()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tomlKind records how a table came to exist, which decides whether it
+// may be defined or extended later
+type tomlKind int
+
+const (
+	tomlImplicit tomlKind = iota // parent of a [table] header, may be defined later
+	tomlDefined                  // defined by a header or as an array of tables element
+	tomlDotted                   // created by a dotted key, may gain more dotted keys
+)
+
+type tomlTable struct {
+	kind   tomlKind
+	values map[string]interface{}
+}
+
+type tomlTableArray struct {
+	tables []*tomlTable
+}
+
+func newTOMLTable(kind tomlKind) *tomlTable {
+	return &tomlTable{kind: kind, values: make(map[string]interface{})}
+}
+
+// data converts the table to the JSONValue data model
+func (t *tomlTable) data() map[string]interface{} {
+	out := make(map[string]interface{}, len(t.values))
+	for k, v := range t.values {
+		switch v := v.(type) {
+		case *tomlTable:
+			out[k] = v.data()
+		case *tomlTableArray:
+			arr := make([]interface{}, len(v.tables))
+			for i, table := range v.tables {
+				arr[i] = table.data()
+			}
+			out[k] = arr
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+type tomlParser struct {
+	data  []byte
+	pos   int
+	depth int
+	root  *tomlTable
+}
+
+func (p *tomlParser) errorAt(offset int, format string, args ...interface{}) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Pos: positionAt(p.data, offset)}
+}
+
+func (p *tomlParser) unexpected() error {
+	if p.pos >= len(p.data) {
+		return p.errorAt(p.pos, "unexpected end of TOML input")
+	}
+	if c := p.data[p.pos]; c == '\n' || c == '\r' {
+		return p.errorAt(p.pos, "unexpected end of line")
+	}
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	return p.errorAt(p.pos, "unexpected character %q", r)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.pos < len(p.data) {
+		return p.data[p.pos]
+	}
+	return 0
+}
+
+// skipSpace skips spaces and tabs
+func (p *tomlParser) skipSpace() {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment up to the end of its line
+func (p *tomlParser) skipComment() error {
+	if p.peek() != '#' {
+		return nil
+	}
+	for ; p.pos < len(p.data) && p.data[p.pos] != '\n'; p.pos++ {
+		if c := p.data[p.pos]; c < 0x20 && c != '\t' && c != '\r' || c == 0x7f {
+			return p.errorAt(p.pos, "control character in comment")
+		}
+	}
+	return nil
+}
+
+// newline consumes a line break, reporting false when there is none
+func (p *tomlParser) newline() bool {
+	if p.peek() == '\n' {
+		p.pos++
+		return true
+	}
+	if p.peek() == '\r' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+		p.pos += 2
+		return true
+	}
+	return false
+}
+
+// endLine checks that only a comment follows on the line and moves past
+// the line break
+func (p *tomlParser) endLine() error {
+	p.skipSpace()
+	if err := p.skipComment(); err != nil {
+		return err
+	}
+	if p.pos < len(p.data) && !p.newline() {
+		return p.errorAt(p.pos, "expected the end of the line, found %s", strconv.QuoteRune(rune(p.data[p.pos])))
+	}
+	return nil
+}
+
+// skipBlank skips whitespace, line breaks and comments inside an array
+func (p *tomlParser) skipBlank() error {
+	for {
+		p.skipSpace()
+		if err := p.skipComment(); err != nil {
+			return err
+		}
+		if !p.newline() {
+			return nil
+		}
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// key reads a possibly dotted key
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace()
+		start := p.pos
+		var key string
+		var err error
+		switch p.peek() {
+		case '"':
+			key, err = p.basicString()
+		case '\'':
+			key, err = p.literalString()
+		default:
+			for p.pos < len(p.data) && isBareKeyChar(p.data[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.unexpected()
+			}
+			key = string(p.data[start:p.pos])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p.pos-start >= 3 && (bytes.HasPrefix(p.data[start:], []byte(`"""`)) || bytes.HasPrefix(p.data[start:], []byte("'''"))) {
+			return nil, p.errorAt(start, "multi-line strings cannot be keys")
+		}
+		keys = append(keys, key)
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// header reads a [table] or [[array of tables]] header and returns the
+// table that following keys belong to
+func (p *tomlParser) header() (*tomlTable, error) {
+	start := p.pos
+	array := p.pos+1 < len(p.data) && p.data[p.pos+1] == '['
+	p.pos++
+	if array {
+		p.pos++
+	}
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ']' || array && (p.pos+1 >= len(p.data) || p.data[p.pos+1] != ']') {
+		return nil, p.unexpected()
+	}
+	p.pos++
+	if array {
+		p.pos++
+	}
+
+	name := strings.Join(keys, ".")
+	t := p.root
+	for _, k := range keys[:len(keys)-1] {
+		switch child := t.values[k].(type) {
+		case nil:
+			next := newTOMLTable(tomlImplicit)
+			t.values[k] = next
+			t = next
+		case *tomlTable:
+			t = child
+		case *tomlTableArray:
+			t = child.tables[len(child.tables)-1]
+		default:
+			return nil, p.errorAt(start, "cannot define table %q: key %q is not a table", name, k)
+		}
+	}
+
+	last := keys[len(keys)-1]
+	next := newTOMLTable(tomlDefined)
+	switch child := t.values[last].(type) {
+	case nil:
+		if array {
+			t.values[last] = &tomlTableArray{tables: []*tomlTable{next}}
+		} else {
+			t.values[last] = next
+		}
+		return next, nil
+	case *tomlTableArray:
+		if array {
+			child.tables = append(child.tables, next)
+			return next, nil
+		}
+	case *tomlTable:
+		if !array && child.kind == tomlImplicit {
+			child.kind = tomlDefined
+			return child, nil
+		}
+	}
+	return nil, p.errorAt(start, "%q is already defined", name)
+}
+
+// keyValue reads a key = value pair into t
+func (p *tomlParser) keyValue(t *tomlTable) error {
+	start := p.pos
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return p.errorAt(p.pos, "expected '=' after key")
+	}
+	p.pos++
+	p.skipSpace()
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys[:len(keys)-1] {
+		switch child := t.values[k].(type) {
+		case nil:
+			next := newTOMLTable(tomlDotted)
+			t.values[k] = next
+			t = next
+		case *tomlTable:
+			if child.kind != tomlDotted {
+				return p.errorAt(start, "cannot add to table %q with a dotted key", k)
+			}
+			t = child
+		default:
+			return p.errorAt(start, "key %q is not a table", k)
+		}
+	}
+	last := keys[len(keys)-1]
+	if _, ok := t.values[last]; ok {
+		return p.errorAt(start, "duplicate key %q", strings.Join(keys, "."))
+	}
+	t.values[last] = value
+	return nil
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	switch p.peek() {
+	case '"':
+		return p.basicString()
+	case '\'':
+		return p.literalString()
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) && (isBareKeyChar(p.data[p.pos]) || strings.IndexByte("+.:", p.data[p.pos]) >= 0) {
+		p.pos++
+	}
+	// a space may separate the date and time of a datetime
+	if p.pos-start == 10 && tomlDate.Match(p.data[start:p.pos]) && p.peek() == ' ' &&
+		p.pos+1 < len(p.data) && p.data[p.pos+1] >= '0' && p.data[p.pos+1] <= '9' {
+		p.pos++
+		for p.pos < len(p.data) && (isBareKeyChar(p.data[p.pos]) || strings.IndexByte("+.:", p.data[p.pos]) >= 0) {
+			p.pos++
+		}
+	}
+	if p.pos == start {
+		return nil, p.unexpected()
+	}
+	return p.scalar(string(p.data[start:p.pos]), start)
+}
+
+var (
+	tomlDecimal  = regexp.MustCompile(`^[-+]?(?:0|[1-9](?:_?[0-9])*)$`)
+	tomlPrefixed = regexp.MustCompile(`^(?:0x[0-9A-Fa-f](?:_?[0-9A-Fa-f])*|0o[0-7](?:_?[0-7])*|0b[01](?:_?[01])*)$`)
+	tomlFloat    = regexp.MustCompile(`^[-+]?(?:0|[1-9](?:_?[0-9])*)(?:\.[0-9](?:_?[0-9])*)?(?:[eE][-+]?[0-9](?:_?[0-9])*)?$`)
+	tomlDate     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	tomlDateTime = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2})(?:[Tt ]([0-9]{2}:[0-9]{2}:[0-9]{2}(?:\.[0-9]+)?)([Zz]|[-+][0-9]{2}:[0-9]{2})?)?$`)
+	tomlTime     = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}:[0-9]{2}(?:\.[0-9]+)?$`)
+)
+
+// scalar types a bare value: a boolean, number or datetime
+func (p *tomlParser) scalar(token string, start int) (interface{}, error) {
+	digits := strings.ReplaceAll(token, "_", "")
+	switch {
+	case token == "true":
+		return true, nil
+	case token == "false":
+		return false, nil
+	case tomlDecimal.MatchString(token):
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return nil, p.errorAt(start, "integer %s out of range", token)
+		}
+		return float64(n), nil
+	case tomlPrefixed.MatchString(token):
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[token[1]]
+		n, err := strconv.ParseInt(digits[2:], base, 64)
+		if err != nil {
+			return nil, p.errorAt(start, "integer %s out of range", token)
+		}
+		return float64(n), nil
+	case tomlFloat.MatchString(token):
+		f, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return nil, p.errorAt(start, "float %s out of range", token)
+		}
+		return f, nil
+	case strings.TrimLeft(token, "+-") == "inf" || strings.TrimLeft(token, "+-") == "nan":
+		return nil, p.errorAt(start, "%s cannot be represented in JSON", token)
+	}
+	if s, ok := tomlDatetime(token); ok {
+		return s, nil
+	}
+	return nil, p.errorAt(start, "invalid value %q", token)
+}
+
+// tomlDatetime validates a TOML datetime and returns it in the RFC 3339
+// form LoadTOML produces
+func tomlDatetime(token string) (string, bool) {
+	if tomlTime.MatchString(token) {
+		_, err := time.Parse("15:04:05", token)
+		return token, err == nil
+	}
+	m := tomlDateTime.FindStringSubmatch(token)
+	if m == nil {
+		return "", false
+	}
+	date, clock, offset := m[1], m[2], strings.ToUpper(m[3])
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", false
+	}
+	if clock == "" {
+		return date, true
+	}
+	if _, err := time.Parse("15:04:05", clock); err != nil {
+		return "", false
+	}
+	s := date + "T" + clock + offset
+	if offset != "" {
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			return "", false
+		}
+	}
+	return s, true
+}
+
+// basicString reads a "basic" string or a multi-line basic string
+// delimited by three double quotes
+func (p *tomlParser) basicString() (string, error) {
+	start := p.pos
+	multi := bytes.HasPrefix(p.data[p.pos:], []byte(`"""`))
+	if multi {
+		p.pos += 3
+		p.newline()
+	} else {
+		p.pos++
+	}
+	var buf []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			if !multi {
+				p.pos++
+				return string(buf), nil
+			}
+			n := 0
+			for p.pos+n < len(p.data) && p.data[p.pos+n] == '"' {
+				n++
+			}
+			if n >= 3 {
+				if n > 5 {
+					return "", p.errorAt(p.pos, "too many quotes at the end of a string")
+				}
+				p.pos += n
+				return string(append(buf, strings.Repeat(`"`, n-3)...)), nil
+			}
+			buf = append(buf, p.data[p.pos:p.pos+n]...)
+			p.pos += n
+		case c == '\\':
+			p.pos++
+			if multi && p.lineEndingBackslash() {
+				continue
+			}
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			buf = utf8.AppendRune(buf, r)
+		case multi && p.newline():
+			buf = append(buf, '\n')
+		default:
+			if c < 0x20 && c != '\t' || c == 0x7f {
+				if c == '\n' || c == '\r' {
+					return "", p.errorAt(start, "unterminated string")
+				}
+				return "", p.errorAt(p.pos, "control character in string")
+			}
+			buf = append(buf, c)
+			p.pos++
+		}
+	}
+	return "", p.errorAt(start, "unterminated string")
+}
+
+// lineEndingBackslash skips a backslash at the end of a line in a
+// multi-line string together with the whitespace and line breaks after it
+func (p *tomlParser) lineEndingBackslash() bool {
+	i := p.pos
+	for i < len(p.data) && (p.data[i] == ' ' || p.data[i] == '\t') {
+		i++
+	}
+	if i >= len(p.data) || p.data[i] != '\n' && p.data[i] != '\r' {
+		return false
+	}
+	for p.pos = i; p.pos < len(p.data); p.pos++ {
+		if c := p.data[p.pos]; c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			break
+		}
+	}
+	return true
+}
+
+// escape decodes the escape sequence after a backslash
+func (p *tomlParser) escape() (rune, error) {
+	start := p.pos - 1
+	switch c := p.peek(); c {
+	case 'b', 't', 'n', 'f', 'r', '"', '\\':
+		p.pos++
+		return map[byte]rune{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', '"': '"', '\\': '\\'}[c], nil
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+1+size <= len(p.data) {
+			n, err := strconv.ParseUint(string(p.data[p.pos+1:p.pos+1+size]), 16, 32)
+			if r := rune(n); err == nil && utf8.ValidRune(r) {
+				p.pos += 1 + size
+				return r, nil
+			}
+		}
+	}
+	return 0, p.errorAt(start, "invalid escape sequence")
+}
+
+// literalString reads a 'literal' string or a multi-line literal string
+// delimited by three single quotes
+func (p *tomlParser) literalString() (string, error) {
+	start := p.pos
+	multi := bytes.HasPrefix(p.data[p.pos:], []byte("'''"))
+	if multi {
+		p.pos += 3
+		p.newline()
+	} else {
+		p.pos++
+	}
+	var buf []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '\'':
+			if !multi {
+				p.pos++
+				return string(buf), nil
+			}
+			n := 0
+			for p.pos+n < len(p.data) && p.data[p.pos+n] == '\'' {
+				n++
+			}
+			if n >= 3 {
+				if n > 5 {
+					return "", p.errorAt(p.pos, "too many quotes at the end of a string")
+				}
+				p.pos += n
+				return string(append(buf, strings.Repeat("'", n-3)...)), nil
+			}
+			buf = append(buf, p.data[p.pos:p.pos+n]...)
+			p.pos += n
+		case multi && p.newline():
+			buf = append(buf, '\n')
+		default:
+			if c < 0x20 && c != '\t' || c == 0x7f {
+				if c == '\n' || c == '\r' {
+					return "", p.errorAt(start, "unterminated string")
+				}
+				return "", p.errorAt(p.pos, "control character in string")
+			}
+			buf = append(buf, c)
+			p.pos++
+		}
+	}
+	return "", p.errorAt(start, "unterminated string")
+}
+
+func (p *tomlParser) enter() error {
+	if p.depth++; p.depth > defaultMaxDepth {
+		return p.errorAt(p.pos, "exceeded max depth of %d", defaultMaxDepth)
+	}
+	return nil
+}
+
+// array reads an array, which may span lines and end with a comma
+func (p *tomlParser) array() ([]interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	start := p.pos
+	p.pos++
+	arr := []interface{}{}
+	for {
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorAt(start, "unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			if p.pos >= len(p.data) {
+				return nil, p.errorAt(start, "unterminated array")
+			}
+			return nil, p.unexpected()
+		}
+	}
+}
+
+// inlineTable reads an inline table, which must fit on one line and has
+// no trailing comma. Inline tables cannot be extended afterwards.
+func (p *tomlParser) inlineTable() (map[string]interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	p.pos++
+	t := newTOMLTable(tomlDefined)
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return t.data(), nil
+	}
+	for {
+		if err := p.keyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			p.skipSpace()
+			if p.peek() == '}' {
+				return nil, p.errorAt(p.pos, "trailing comma in inline table")
+			}
+		case '}':
+			p.pos++
+			return t.data(), nil
+		default:
+			return nil, p.unexpected()
+		}
+	}
+}
+
+// DumpTOML converts an object to a TOML document. Keys are sorted, with
+// each table's plain values before its sub-tables, and arrays whose
+// elements are all objects become arrays of tables. Integral numbers are
+// written as integers, and strings in the datetime forms LoadTOML
+// produces are written as TOML datetimes. TOML has no null, so a null
+// anywhere in the value is an error.
+func (jv *JSONValue) DumpTOML() ([]byte, error) {
+	value, err := genericValue(jv.data)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot convert non-object type to TOML")
+	}
+	e := &tomlEmitter{}
+	if err := e.table(obj, nil, false); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+type tomlEmitter struct {
+	buf []byte
+}
+
+// isTOMLTableArray reports whether value is written as an array of tables
+func isTOMLTableArray(value interface{}) bool {
+	arr, ok := value.([]interface{})
+	if !ok || len(arr) == 0 {
+		return false
+	}
+	for _, item := range arr {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// table writes obj as the table at path. element marks an element of an
+// array of tables.
+func (e *tomlEmitter) table(obj map[string]interface{}, path []string, element bool) error {
+	var plain, tables, arrays []string
+	for _, k := range sortedKeys(obj) {
+		switch v := obj[k].(type) {
+		case map[string]interface{}:
+			if len(v) > 0 {
+				tables = append(tables, k)
+				continue
+			}
+		case []interface{}:
+			if isTOMLTableArray(v) {
+				arrays = append(arrays, k)
+				continue
+			}
+		}
+		plain = append(plain, k)
+	}
+
+	if element || len(path) > 0 && len(plain) > 0 {
+		if len(e.buf) > 0 {
+			e.buf = append(e.buf, '\n')
+		}
+		open, closing := "[", "]"
+		if element {
+			open, closing = "[[", "]]"
+		}
+		e.buf = append(e.buf, open...)
+		for i, k := range path {
+			if i > 0 {
+				e.buf = append(e.buf, '.')
+			}
+			e.buf = appendTOMLKey(e.buf, k)
+		}
+		e.buf = append(e.buf, closing...)
+		e.buf = append(e.buf, '\n')
+	}
+
+	for _, k := range plain {
+		e.buf = appendTOMLKey(e.buf, k)
+		e.buf = append(e.buf, " = "...)
+		if err := e.value(obj[k], tomlPath(path, k)); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, '\n')
+	}
+	child := func(k string) []string {
+		return append(path[:len(path):len(path)], k)
+	}
+	for _, k := range tables {
+		if err := e.table(obj[k].(map[string]interface{}), child(k), false); err != nil {
+			return err
+		}
+	}
+	for _, k := range arrays {
+		for _, item := range obj[k].([]interface{}) {
+			if err := e.table(item.(map[string]interface{}), child(k), true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// value writes an inline value. where names it in error messages.
+func (e *tomlEmitter) value(value interface{}, where string) error {
+	switch v := value.(type) {
+	case nil:
+		return fmt.Errorf("cannot encode null at %s in TOML", where)
+	case bool:
+		e.buf = strconv.AppendBool(e.buf, v)
+	case json.Number:
+		e.buf = append(e.buf, v...)
+		if !strings.ContainsAny(string(v), ".eE") {
+			if _, err := strconv.ParseInt(string(v), 10, 64); err != nil {
+				// beyond the range of TOML integers
+				e.buf = append(e.buf, ".0"...)
+			}
+		}
+	case string:
+		if s, ok := tomlDatetime(v); ok && s == v {
+			e.buf = append(e.buf, v...)
+		} else {
+			e.buf = appendTOMLString(e.buf, v)
+		}
+	case []interface{}:
+		e.buf = append(e.buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				e.buf = append(e.buf, ", "...)
+			}
+			if err := e.value(item, where+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, ']')
+	case map[string]interface{}:
+		e.buf = append(e.buf, '{')
+		for i, k := range sortedKeys(v) {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.buf = append(e.buf, ' ')
+			e.buf = appendTOMLKey(e.buf, k)
+			e.buf = append(e.buf, " = "...)
+			if err := e.value(v[k], where+"."+k); err != nil {
+				return err
+			}
+		}
+		if len(v) > 0 {
+			e.buf = append(e.buf, ' ')
+		}
+		e.buf = append(e.buf, '}')
+	default:
+		return fmt.Errorf("cannot encode %T at %s in TOML", v, where)
+	}
+	return nil
+}
+
+func tomlPath(path []string, key string) string {
+	return strings.Join(append(path[:len(path):len(path)], key), ".")
+}
+
+func appendTOMLKey(buf []byte, key string) []byte {
+	for i := 0; i < len(key); i++ {
+		if !isBareKeyChar(key[i]) {
+			return appendTOMLString(buf, key)
+		}
+	}
+	if key == "" {
+		return append(buf, `""`...)
+	}
+	return append(buf, key...)
+}
+
+// appendTOMLString writes s as a basic string
+func appendTOMLString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, `\uFFFD`...)
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, `\n`...)
+		case r == '\t':
+			buf = append(buf, `\t`...)
+		case r == '\r':
+			buf = append(buf, `\r`...)
+		case r < 0x20 || r == 0x7f:
+			buf = fmt.Appendf(buf, `\u%04X`, r)
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
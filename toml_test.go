@@ -0,0 +1,255 @@
+package easyjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadTOML(t *testing.T) {
+	src := `# This is a TOML document
+
+title = "TOML Example"
+
+[owner]
+name = "Tom Preston-Werner"
+dob = 1979-05-27T07:32:00-08:00
+
+[database]
+enabled = true
+ports = [ 8000, 8001, 8002 ]
+data = [ ["delta", "phi"], [3.14] ]
+temp_targets = { cpu = 79.5, case = 72.0 }
+
+[servers]
+
+[servers.alpha]
+ip = "10.0.0.1"
+role = "frontend"
+
+[servers.beta]
+ip = "10.0.0.2"
+role = "backend"
+
+[[products]]
+name = "Hammer"
+sku = 738594937
+
+[[products]]  # empty table within the array
+
+[[products]]
+name = "Nail"
+sku = 284758393
+color = "gray"
+
+[[fruits]]
+name = "apple"
+
+[fruits.physical]
+color = "red"
+shape = "round"
+
+[[fruits.varieties]]
+name = "red delicious"
+
+[[fruits]]
+name = "banana"
+`
+	jv, err := LoadTOML([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"database":{"data":[["delta","phi"],[3.14]],"enabled":true,"ports":[8000,8001,8002],"temp_targets":{"case":72,"cpu":79.5}},` +
+		`"fruits":[{"name":"apple","physical":{"color":"red","shape":"round"},"varieties":[{"name":"red delicious"}]},{"name":"banana"}],` +
+		`"owner":{"dob":"1979-05-27T07:32:00-08:00","name":"Tom Preston-Werner"},` +
+		`"products":[{"name":"Hammer","sku":738594937},{},{"color":"gray","name":"Nail","sku":284758393}],` +
+		`"servers":{"alpha":{"ip":"10.0.0.1","role":"frontend"},"beta":{"ip":"10.0.0.2","role":"backend"}},"title":"TOML Example"}`
+	if got := dumps(t, jv); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestLoadTOMLValues(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{`a = "tab\tuni\u00e9\U0001F600 \"q\" \\"`, `{"a":"tab\tunié😀 \"q\" \\"}`},
+		{`a = 'C:\Users\nodejs'`, `{"a":"C:\\Users\\nodejs"}`},
+		{"a = \"\"\"\nRoses\r\nViolets\"\"\"", `{"a":"Roses\nViolets"}`},
+		{"a = \"\"\"\\\n  The quick \\\n\n  brown fox.\\\n  \"\"\"", `{"a":"The quick brown fox."}`},
+		{`a = """Here are two quotes: "". ""end"""""`, `{"a":"Here are two quotes: \"\". \"\"end\"\""}`},
+		{"a = '''\nfirst\n  raw \\n line'''", `{"a":"first\n  raw \\n line"}`},
+		{`a = ''''That's it''''`, `{"a":"'That's it'"}`},
+		{"a = +99\nb = -17\nc = 0\nd = 1_000\ne = 0xDEAD_beef\nf = 0o755\ng = 0b1101", `{"a":99,"b":-17,"c":0,"d":1000,"e":3735928559,"f":493,"g":13}`},
+		{"a = 6.626e-34\nb = -0.01\nc = 5e+22\nd = 1E6\ne = 224_617.445_991", `{"a":6.626e-34,"b":-0.01,"c":5e+22,"d":1000000,"e":224617.445991}`},
+		{"a = true\nb = false", `{"a":true,"b":false}`},
+		{"a = 1979-05-27 07:32:00z", `{"a":"1979-05-27T07:32:00Z"}`},
+		{"a = 1979-05-27T00:32:00.999999+07:00", `{"a":"1979-05-27T00:32:00.999999+07:00"}`},
+		{"a = 1979-05-27T07:32:00.5", `{"a":"1979-05-27T07:32:00.5"}`},
+		{"a = 1979-05-27", `{"a":"1979-05-27"}`},
+		{"a = 07:32:00", `{"a":"07:32:00"}`},
+		{"a = [\n  1,\n  'x', # comment\n  [],\n  {},\n]", `{"a":[1,"x",[],{}]}`},
+		{"a = { x = 1, y.z = 2, w = { v = [] } }", `{"a":{"w":{"v":[]},"x":1,"y":{"z":2}}}`},
+		{"site.\"google.com\" = true\n'quoted key' = 1\n3.14 = 'pi'\n\"\" = 0", `{"":0,"3":{"14":"pi"},"quoted key":1,"site":{"google.com":true}}`},
+		{"fruit.apple.color = 'red'\nfruit.apple.smooth = true\nfruit . orange = 2", `{"fruit":{"apple":{"color":"red","smooth":true},"orange":2}}`},
+		{"[x.y.z]\na = 1\n[x]\nb = 2", `{"x":{"b":2,"y":{"z":{"a":1}}}}`},
+		{"[fruit]\napple.color = 'red'\n[fruit.apple.texture]\nsmooth = true", `{"fruit":{"apple":{"color":"red","texture":{"smooth":true}}}}`},
+		{"[ a . 'b' ]\nc = 1\n[[ d ]]\n", `{"a":{"b":{"c":1}},"d":[{}]}`},
+		{"\xef\xbb\xbfa = 1\r\n\r\n# done", `{"a":1}`},
+		{"", `{}`},
+	}
+	for _, tt := range tests {
+		jv, err := LoadTOML([]byte(tt.src))
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+			continue
+		}
+		if got := dumps(t, jv); got != tt.want {
+			t.Errorf("%q: expected %s, got %s", tt.src, tt.want, got)
+		}
+	}
+}
+
+func TestLoadTOMLErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+		msg  string
+	}{
+		{"a = 1\na = 2\n", 2, "duplicate key"},
+		{"[a]\nx = 1\n\n[a]\n", 4, "already defined"},
+		{"[a.b]\n[a]\n[a]\n", 3, "already defined"},
+		{"a = {x = 1}\n[a]\n", 2, "already defined"},
+		{"a = {x = 1}\n[a.b]\n", 2, "not a table"},
+		{"a = {x = 1}\na.y = 2\n", 2, "not a table"},
+		{"[a.b]\nc = 1\n[a]\nb.d = 2\n", 4, "dotted key"},
+		{"a.b = 1\n[a]\n", 2, "already defined"},
+		{"[fruit]\napple.color = 'red'\n[fruit.apple]\n", 3, "already defined"},
+		{"a = []\n[[a]]\n", 2, "already defined"},
+		{"[[a]]\n[a]\n", 2, "already defined"},
+		{"a = 1\n\nb = nope\n", 3, "invalid value"},
+		{"a = 01\n", 1, "invalid value"},
+		{"a = 1.\n", 1, "invalid value"},
+		{"a = 0x\n", 1, "invalid value"},
+		{"a = 1979-02-30\n", 1, "invalid value"},
+		{"a = 9223372036854775808\n", 1, "out of range"},
+		{"a = inf\n", 1, "cannot be represented"},
+		{"x = 1\na = -nan\n", 2, "cannot be represented"},
+		{"a = \"open\nb = 1\n", 1, "unterminated string"},
+		{"a = '''open\n\nb = 1\n", 1, "unterminated string"},
+		{"a = \"\\q\"\n", 1, "invalid escape"},
+		{"a = \"\\uD800\"\n", 1, "invalid escape"},
+		{"a = \"x\x01\"\n", 1, "control character"},
+		{"a = 1 b = 2\n", 1, "end of the line"},
+		{"[a] b = 1\n", 1, "end of the line"},
+		{"a = {x = 1,}\n", 1, "trailing comma"},
+		{"a = {x = 1,\ny = 2}\n", 1, "end of line"},
+		{"a = [1 2]\n", 1, "unexpected character"},
+		{"a = [1,\n2\n", 1, "unterminated array"},
+		{"a\n", 1, "expected '='"},
+		{"= 1\n", 1, "unexpected character"},
+		{"[a\n", 1, "unexpected end of line"},
+		{"[[a]\n", 1, "unexpected"},
+		{"\"\"\"k\"\"\" = 1\n", 1, "cannot be keys"},
+		{"a = 1 # bell\x07\n", 1, "control character"},
+		{"a = 1\rb = 2\n", 1, "end of the line"},
+	}
+	for _, tt := range tests {
+		_, err := LoadTOML([]byte(tt.src))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected *SyntaxError, got %v", tt.src, err)
+			continue
+		}
+		if syntaxErr.Pos.Line != tt.line || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%q: expected %q on line %d, got %v", tt.src, tt.msg, tt.line, err)
+		}
+	}
+
+	deep := "a = " + strings.Repeat("[", defaultMaxDepth+1)
+	if _, err := LoadTOML([]byte(deep)); err == nil || !strings.Contains(err.Error(), "max depth") {
+		t.Errorf("Expected depth error, got %v", err)
+	}
+}
+
+func TestDumpTOML(t *testing.T) {
+	jv := mustLoads(t, `{
+		"title": "Example",
+		"owner": {"name": "Tom", "dob": "1979-05-27T07:32:00-08:00"},
+		"database": {"ports": [8000, 8001], "limits": {"max": 1.5}, "inline": [{"a": 1}, 2], "empty": {}},
+		"servers": {"alpha": {"ip": "10.0.0.1"}},
+		"products": [{"name": "Hammer", "parts": [{"id": 1}]}, {}],
+		"key with space": "line\nbreak\t\"q\"",
+		"big": 1e20,
+		"times": ["07:32:00", "1979-05-27", "1979-05-27 07:32:00"]
+	}`)
+	out, err := jv.DumpTOML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `big = 100000000000000000000.0
+"key with space" = "line\nbreak\t\"q\""
+times = [07:32:00, 1979-05-27, "1979-05-27 07:32:00"]
+title = "Example"
+
+[database]
+empty = {}
+inline = [{ a = 1 }, 2]
+ports = [8000, 8001]
+
+[database.limits]
+max = 1.5
+
+[owner]
+dob = 1979-05-27T07:32:00-08:00
+name = "Tom"
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[[products]]
+name = "Hammer"
+
+[[products.parts]]
+id = 1
+
+[[products]]
+`
+	if string(out) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, out)
+	}
+
+	for _, bad := range []string{`{"a": {"b": [1, null]}}`, `[1]`, `"x"`} {
+		if _, err := mustLoads(t, bad).DumpTOML(); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+	if _, err := mustLoads(t, `{"a": {"b": null}}`).DumpTOML(); err == nil || !strings.Contains(err.Error(), "a.b") {
+		t.Errorf("Expected the null's path in the error, got %v", err)
+	}
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	docs := []string{
+		`{}`,
+		`{"a": {"b": {"c": {"d": 1}}}, "e": [{"f": [{"g": {"h": "i"}}]}, {"f": []}]}`,
+		`{"": "", "a.b": {"c d": [["x"], [], [{}]]}, "é": "\u0001\u007f\ud83d\ude00"}`,
+		`{"n": [-0.000001, 1e21, 123456789012, -5, 0.5], "s": ["true", "1", "1979-05-27T07:32:00Z", "1979-13-01"]}`,
+		`{"t": [{"a": {}}, {"a": {"b": 1}}], "u": {"v": {}}}`,
+	}
+	for _, doc := range docs {
+		jv := mustLoads(t, doc)
+		out, err := jv.DumpTOML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := LoadTOML(out)
+		if err != nil {
+			t.Errorf("Reading back\n%s\nfailed: %v", out, err)
+			continue
+		}
+		if !back.Equal(jv, EqualOptions{}) {
+			t.Errorf("Round trip of %s gave %s via\n%s", doc, dumps(t, back), out)
+		}
+	}
+}
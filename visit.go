@@ -0,0 +1,210 @@
+package easyjson
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Node describes a value reached by Visit or Transform
+type Node struct {
+	Path   string      // JSON Pointer (RFC 6901) of the value, "" for the root
+	Parent *JSONValue  // object or array holding the value, nil for the root
+	Key    interface{} // string key or int index within Parent, nil for the root
+	Value  *JSONValue
+	Depth  int // 0 for the root
+}
+
+// VisitAction tells Visit how to continue after a hook returns
+type VisitAction int
+
+const (
+	VisitContinue VisitAction = iota // visit the node's children
+	VisitSkip                        // leave out the node's children
+	VisitStop                        // end the walk
+)
+
+// Visit walks the tree depth-first, calling pre before a node's children
+// and post after them. Either hook may be nil. Returning VisitSkip from pre
+// leaves out the node's children (post is still called for the node) and
+// returning VisitStop from either hook ends the walk. Object members are
+// visited in sorted key order.
+func (jv *JSONValue) Visit(pre, post func(Node) VisitAction) {
+	visit(Node{Value: jv}, pre, post)
+}
+
+func visit(n Node, pre, post func(Node) VisitAction) bool {
+	action := VisitContinue
+	if pre != nil {
+		action = pre(n)
+	}
+	if action == VisitStop {
+		return false
+	}
+	if action != VisitSkip {
+		for child := range children(n) {
+			if !visit(child, pre, post) {
+				return false
+			}
+		}
+	}
+	return post == nil || post(n) != VisitStop
+}
+
+// children yields the nodes for the members of n's value
+func children(n Node) func(yield func(Node) bool) {
+	return func(yield func(Node) bool) {
+		switch v := n.Value.data.(type) {
+		case map[string]interface{}:
+			for _, k := range sortedKeys(v) {
+				child := Node{
+					Path:   n.Path + "/" + escapePointer(k),
+					Parent: n.Value,
+					Key:    k,
+					Value:  &JSONValue{data: v[k], parent: n.Value, key: k},
+					Depth:  n.Depth + 1,
+				}
+				if !yield(child) {
+					return
+				}
+			}
+		case []interface{}:
+			for i, item := range v {
+				child := Node{
+					Path:   n.Path + "/" + strconv.Itoa(i),
+					Parent: n.Value,
+					Key:    i,
+					Value:  &JSONValue{data: item, parent: n.Value, key: i},
+					Depth:  n.Depth + 1,
+				}
+				if !yield(child) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TransformNode is the node passed to a Transform function. Calling its
+// methods changes how the node appears in the result; a node left alone
+// is kept and its children are transformed in turn.
+type TransformNode struct {
+	Node
+
+	deleted     bool
+	replaced    bool
+	replacement interface{}
+	renamed     bool
+	name        string
+	before      []interface{}
+	after       []interface{}
+	members     []transformMember
+}
+
+type transformMember struct {
+	key   string
+	value interface{}
+}
+
+// Replace puts value in place of the node. The replacement's children
+// are not transformed.
+func (n *TransformNode) Replace(value interface{}) {
+	n.replaced, n.replacement = true, value
+}
+
+// Delete removes the node from its parent. Deleting the root yields null.
+func (n *TransformNode) Delete() {
+	n.deleted = true
+}
+
+// Rename changes the key of an object member
+func (n *TransformNode) Rename(key string) {
+	if _, ok := n.Key.(string); ok {
+		n.renamed, n.name = true, key
+	}
+}
+
+// InsertBefore adds values to the parent array ahead of the node
+func (n *TransformNode) InsertBefore(values ...interface{}) {
+	n.before = append(n.before, values...)
+}
+
+// InsertAfter adds values to the parent array after the node
+func (n *TransformNode) InsertAfter(values ...interface{}) {
+	n.after = append(n.after, values...)
+}
+
+// InsertMember adds a member to the parent object, replacing any member
+// already using key
+func (n *TransformNode) InsertMember(key string, value interface{}) {
+	n.members = append(n.members, transformMember{key, value})
+}
+
+// Transform returns a copy of the document rebuilt by calling fn on every
+// node, parents before children and object members in sorted key order.
+// The original is left untouched. Inserted and replacement values are
+// not passed to fn.
+func (jv *JSONValue) Transform(fn func(n *TransformNode)) *JSONValue {
+	n := &TransformNode{Node: Node{Value: &JSONValue{data: deepCopy(jv.data)}}}
+	fn(n)
+	switch {
+	case n.deleted:
+		return &JSONValue{data: nil}
+	case n.replaced:
+		return &JSONValue{data: n.replacement}
+	}
+	return &JSONValue{data: transformChildren(n.Node, fn)}
+}
+
+// transformChildren transforms the members of parent's value and returns
+// the rebuilt value
+func transformChildren(parent Node, fn func(n *TransformNode)) interface{} {
+	switch parent.Value.data.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		for child := range children(parent) {
+			n := &TransformNode{Node: child}
+			fn(n)
+			if !n.deleted {
+				key := child.Key.(string)
+				if n.renamed {
+					key = n.name
+				}
+				if n.replaced {
+					out[key] = n.replacement
+				} else {
+					out[key] = transformChildren(n.Node, fn)
+				}
+			}
+			for _, m := range n.members {
+				out[m.key] = m.value
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0)
+		for child := range children(parent) {
+			n := &TransformNode{Node: child}
+			fn(n)
+			out = append(out, n.before...)
+			if !n.deleted {
+				if n.replaced {
+					out = append(out, n.replacement)
+				} else {
+					out = append(out, transformChildren(n.Node, fn))
+				}
+			}
+			out = append(out, n.after...)
+		}
+		return out
+	}
+	return parent.Value.data
+}
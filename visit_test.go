@@ -0,0 +1,126 @@
+package easyjson
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestVisitOrder(t *testing.T) {
+	jv, _ := Loads(`{"b": [1, {"c": true}], "a": null}`)
+
+	var events []string
+	jv.Visit(func(n Node) VisitAction {
+		events = append(events, fmt.Sprintf("pre %s %v %d", n.Path, n.Key, n.Depth))
+		return VisitContinue
+	}, func(n Node) VisitAction {
+		events = append(events, "post "+n.Path)
+		return VisitContinue
+	})
+
+	want := []string{
+		"pre  <nil> 0",
+		"pre /a a 1", "post /a",
+		"pre /b b 1",
+		"pre /b/0 0 2", "post /b/0",
+		"pre /b/1 1 2",
+		"pre /b/1/c c 3", "post /b/1/c",
+		"post /b/1",
+		"post /b",
+		"post ",
+	}
+	if strings.Join(events, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected visit order:\n%s", strings.Join(events, "\n"))
+	}
+}
+
+func TestVisitSkipAndStop(t *testing.T) {
+	jv, _ := Loads(`{"a": {"x": 1}, "b": {"y": 2}, "c": 3}`)
+
+	var paths []string
+	jv.Visit(func(n Node) VisitAction {
+		paths = append(paths, n.Path)
+		if n.Path == "/a" {
+			return VisitSkip
+		}
+		if n.Path == "/b/y" {
+			return VisitStop
+		}
+		return VisitContinue
+	}, nil)
+	if strings.Join(paths, " ") != " /a /b /b/y" {
+		t.Errorf("Unexpected paths %q", paths)
+	}
+
+	posts := 0
+	jv.Visit(nil, func(n Node) VisitAction {
+		posts++
+		return VisitStop
+	})
+	if posts != 1 {
+		t.Errorf("Expected post hook to stop the walk, called %d times", posts)
+	}
+
+	jv.Visit(func(n Node) VisitAction {
+		if n.Parent != nil && n.Parent.Get(n.Key).Raw() == nil && n.Value.Raw() != nil {
+			t.Errorf("Parent and key do not locate %s", n.Path)
+		}
+		return VisitContinue
+	}, nil)
+}
+
+func TestTransform(t *testing.T) {
+	jv, _ := Loads(`{"user_name": "ann", "age": "31", "tags": ["a", null, "b"], "meta": null, "nested": {"drop": null, "keep": 1}}`)
+	before, _ := jv.Dumps()
+
+	out := jv.Transform(func(n *TransformNode) {
+		switch {
+		case n.Value.IsNull():
+			n.Delete()
+		case n.Key == "user_name":
+			n.Rename("userName")
+			n.InsertMember("nameLength", float64(len(n.Value.AsString())))
+		case n.Key == "age":
+			n.Replace(float64(n.Value.AsInt()))
+		case n.Path == "/tags/0":
+			n.InsertBefore("first")
+			n.InsertAfter("x", "y")
+		}
+	})
+
+	got, _ := out.Dumps()
+	want := `{"age":31,"nameLength":3,"nested":{"keep":1},"tags":["first","a","x","y","b"],"userName":"ann"}`
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+	if after, _ := jv.Dumps(); after != before {
+		t.Error("Transform modified the original")
+	}
+}
+
+func TestTransformRoot(t *testing.T) {
+	jv := New(map[string]interface{}{"a": 1})
+	if !jv.Transform(func(n *TransformNode) { n.Delete() }).IsNull() {
+		t.Error("Expected deleting the root to give null")
+	}
+	if s, _ := jv.Transform(func(n *TransformNode) {
+		if n.Parent == nil {
+			n.Replace([]interface{}{})
+		}
+	}).Dumps(); s != "[]" {
+		t.Errorf("Expected replaced root, got %s", s)
+	}
+
+	// Edits through Value are kept and their children still transformed
+	out := jv.Transform(func(n *TransformNode) {
+		if n.Parent == nil {
+			n.Value.Set("b", map[string]interface{}{"c": "x"})
+		}
+		if n.Key == "c" {
+			n.Replace("y")
+		}
+	})
+	if s, _ := out.Dumps(); s != `{"a":1,"b":{"c":"y"}}` {
+		t.Errorf("Unexpected result %s", s)
+	}
+}
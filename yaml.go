@@ -0,0 +1,1336 @@
+package easyjson
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// LoadYAML parses a YAML document into a JSONValue. It accepts the
+// JSON-compatible part of YAML 1.2: block and flow mappings and
+// sequences, plain, quoted and block scalars, comments, and anchors and
+// aliases, which are resolved to copies. Plain scalars are typed with the
+// YAML 1.2 core schema, so 1 is a number, true a bool and ~ null. Mapping
+// keys become strings. Errors are *SyntaxError values with line numbers.
+//
+// The input must hold at most one document; use LoadYAMLAll for streams.
+// An empty document is null.
+func LoadYAML(data []byte) (*JSONValue, error) {
+	docs, err := LoadYAMLAll(data)
+	if err != nil {
+		return nil, err
+	}
+	switch len(docs) {
+	case 0:
+		return &JSONValue{data: nil}, nil
+	case 1:
+		return docs[0], nil
+	}
+	return nil, fmt.Errorf("expected one YAML document, found %d", len(docs))
+}
+
+// LoadYAMLAll parses every document of a YAML stream, such as one whose
+// documents are separated by "---" lines
+func LoadYAMLAll(data []byte) ([]*JSONValue, error) {
+	y := &yamlParser{data: data}
+	if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		y.pos = 3
+	}
+	var docs []*JSONValue
+	for {
+		value, ok, err := y.document()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return docs, nil
+		}
+		docs = append(docs, &JSONValue{data: value})
+	}
+}
+
+// yamlMaxAliasNodes limits how many nodes aliases may copy into a stream,
+// so a small document of nested aliases cannot expand without bound
+const yamlMaxAliasNodes = 1 << 20
+
+type yamlParser struct {
+	data    []byte
+	pos     int
+	depth   int
+	anchors map[string]interface{}
+	aliased int // nodes copied by aliases so far
+}
+
+func (y *yamlParser) errorAt(offset int, format string, args ...interface{}) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Pos: positionAt(y.data, offset)}
+}
+
+func (y *yamlParser) unexpected() error {
+	if y.pos >= len(y.data) {
+		return y.errorAt(y.pos, "unexpected end of YAML input")
+	}
+	r, _ := utf8.DecodeRune(y.data[y.pos:])
+	return y.errorAt(y.pos, "unexpected character %q", r)
+}
+
+func (y *yamlParser) peek() byte {
+	return y.byteAt(y.pos)
+}
+
+func (y *yamlParser) byteAt(i int) byte {
+	if i < len(y.data) {
+		return y.data[i]
+	}
+	return 0
+}
+
+// blankAt reports whether offset i holds a space, tab or line break, or
+// is the end of input
+func (y *yamlParser) blankAt(i int) bool {
+	if i >= len(y.data) {
+		return true
+	}
+	switch y.data[i] {
+	case ' ', '\t', '\n', '\r':
+		return true
+	}
+	return false
+}
+
+func isFlowIndicator(c byte) bool {
+	return c == ',' || c == '[' || c == ']' || c == '{' || c == '}'
+}
+
+func (y *yamlParser) lineStart(i int) int {
+	for i > 0 && y.data[i-1] != '\n' {
+		i--
+	}
+	return i
+}
+
+func (y *yamlParser) column() int {
+	return y.pos - y.lineStart(y.pos)
+}
+
+// firstOnLine reports whether only whitespace precedes pos on its line
+func (y *yamlParser) firstOnLine() bool {
+	for i := y.lineStart(y.pos); i < y.pos; i++ {
+		if y.data[i] != ' ' && y.data[i] != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// markerAt reports whether a "---" or "..." document marker starts at i
+func (y *yamlParser) markerAt(i int) bool {
+	if i+3 > len(y.data) || i != y.lineStart(i) {
+		return false
+	}
+	s := string(y.data[i : i+3])
+	return (s == "---" || s == "...") && y.blankAt(i+3)
+}
+
+// skipBlank skips whitespace, comments and line breaks in block context
+// and reports whether it crossed a line break
+func (y *yamlParser) skipBlank() (bool, error) {
+	newline, tab := false, -1
+	for y.pos < len(y.data) {
+		switch y.data[y.pos] {
+		case ' ', '\r':
+		case '\t':
+			if tab < 0 {
+				tab = y.pos
+			}
+		case '\n':
+			newline, tab = true, -1
+		case '#':
+			for y.pos < len(y.data) && y.data[y.pos] != '\n' {
+				y.pos++
+			}
+			continue
+		default:
+			if tab >= 0 && y.firstOnLine() {
+				return newline, y.errorAt(tab, "tabs are not allowed in indentation")
+			}
+			return newline, nil
+		}
+		y.pos++
+	}
+	return newline, nil
+}
+
+// skipFlowBlank skips whitespace, comments and line breaks inside a flow
+// collection, where indentation does not matter
+func (y *yamlParser) skipFlowBlank() {
+	for y.pos < len(y.data) {
+		switch y.data[y.pos] {
+		case ' ', '\t', '\n', '\r':
+			y.pos++
+		case '#':
+			for y.pos < len(y.data) && y.data[y.pos] != '\n' {
+				y.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endNode checks that only comments follow a node on its line and moves
+// to the next content
+func (y *yamlParser) endNode() error {
+	if _, err := y.skipBlank(); err != nil {
+		return err
+	}
+	if y.pos < len(y.data) && !y.firstOnLine() {
+		return y.unexpected()
+	}
+	return nil
+}
+
+// document parses the next document of the stream, reporting false at
+// its end
+func (y *yamlParser) document() (interface{}, bool, error) {
+	y.anchors = make(map[string]interface{})
+	directives := false
+	for {
+		if _, err := y.skipBlank(); err != nil {
+			return nil, false, err
+		}
+		if y.pos >= len(y.data) {
+			if directives {
+				return nil, false, y.errorAt(y.pos, "expected '---' after directives")
+			}
+			return nil, false, nil
+		}
+		if y.column() == 0 && y.peek() == '%' {
+			directives = true
+			for y.pos < len(y.data) && y.data[y.pos] != '\n' {
+				y.pos++
+			}
+			continue
+		}
+		if !directives && y.markerAt(y.pos) && y.peek() == '.' {
+			y.pos += 3
+			continue
+		}
+		break
+	}
+
+	explicit := y.markerAt(y.pos) && y.peek() == '-'
+	if explicit {
+		y.pos += 3
+	} else if directives {
+		return nil, false, y.errorAt(y.pos, "expected '---' after directives")
+	}
+	value, err := y.node(-1, explicit, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := y.endNode(); err != nil {
+		return nil, false, err
+	}
+	if y.pos < len(y.data) {
+		if !y.markerAt(y.pos) {
+			return nil, false, y.unexpected()
+		}
+		if y.peek() == '.' {
+			y.pos += 3
+		}
+	}
+	return value, true, nil
+}
+
+// endsNode reports whether a node being parsed at pos is empty because
+// its content would belong to an enclosing collection
+func (y *yamlParser) endsNode(parent int, seqAtParent, newline bool) bool {
+	if y.pos >= len(y.data) || y.markerAt(y.pos) {
+		return true
+	}
+	if !newline {
+		return false
+	}
+	col := y.column()
+	if col > parent {
+		return false
+	}
+	return !seqAtParent || col < parent || y.peek() != '-' || !y.blankAt(y.pos+1)
+}
+
+// node parses a block node inside a collection at column parent. inline
+// is set on the line of a "key:" or "---" indicator, where no block
+// collection may start, and seqAtParent lets a mapping value be a
+// sequence indented like its key.
+func (y *yamlParser) node(parent int, inline, seqAtParent bool) (interface{}, error) {
+	if y.depth++; y.depth > defaultMaxDepth {
+		return nil, y.errorAt(y.pos, "exceeded max depth of %d", defaultMaxDepth)
+	}
+	defer func() { y.depth-- }()
+
+	var anchor, tag string
+	tagPos := 0
+	// sameLine is set while properties share a line with the content
+	sameLine := false
+	for {
+		newline, err := y.skipBlank()
+		if err != nil {
+			return nil, err
+		}
+		if newline {
+			inline, sameLine = false, false
+		}
+		if y.endsNode(parent, seqAtParent, newline) {
+			value, err := y.resolve("", true, tag, tagPos)
+			return y.anchor(anchor, value), err
+		}
+		if c := y.peek(); c != '&' && c != '!' {
+			break
+		}
+		sameLine = true
+		if y.peek() == '&' {
+			y.pos++
+			anchor = y.name()
+		} else {
+			tagPos = y.pos
+			if tag, err = y.tag(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	start, col := y.pos, y.column()
+	var value interface{}
+	var err error
+	switch c := y.peek(); {
+	case c == '*':
+		if anchor != "" || tag != "" {
+			return nil, y.errorAt(start, "an alias cannot have an anchor or tag")
+		}
+		return y.alias()
+	case c == '-' && y.blankAt(y.pos+1):
+		if inline {
+			return nil, y.errorAt(start, "block sequence is not allowed here")
+		}
+		value, err = y.sequence(col)
+	case c == '?' && y.blankAt(y.pos+1):
+		return nil, y.errorAt(start, "explicit mapping keys are not supported")
+	case c == '|' || c == '>':
+		var text string
+		if text, err = y.blockScalar(parent); err != nil {
+			return nil, err
+		}
+		value, err = y.resolve(text, false, tag, tagPos)
+		return y.anchor(anchor, value), err
+	case c == '[' || c == '{':
+		value, err = y.flow()
+	default:
+		var text string
+		var plain, multiline bool
+		if text, plain, multiline, err = y.scalar(parent, false); err != nil {
+			return nil, err
+		}
+		if !y.atKeyIndicator() {
+			if tag == "" {
+				tagPos = start
+			}
+			value, err = y.resolve(text, plain, tag, tagPos)
+			return y.anchor(anchor, value), err
+		}
+		if inline {
+			return nil, y.errorAt(y.pos, "mapping values are not allowed here")
+		}
+		if sameLine {
+			return nil, y.errorAt(start, "properties on mapping keys are not supported")
+		}
+		if multiline {
+			return nil, y.errorAt(start, "mapping keys must be on a single line")
+		}
+		value, err = y.mapping(col, text, start)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := y.checkCollectionTag(value, tag, tagPos); err != nil {
+		return nil, err
+	}
+	return y.anchor(anchor, value), nil
+}
+
+// atKeyIndicator reports whether a ':' mapping indicator follows,
+// possibly after spaces, and moves to it
+func (y *yamlParser) atKeyIndicator() bool {
+	i := y.pos
+	for i < len(y.data) && (y.data[i] == ' ' || y.data[i] == '\t') {
+		i++
+	}
+	if y.byteAt(i) == ':' && y.blankAt(i+1) {
+		y.pos = i
+		return true
+	}
+	return false
+}
+
+// anchor records value under name when an anchor was given
+func (y *yamlParser) anchor(name string, value interface{}) interface{} {
+	if name != "" {
+		y.anchors[name] = value
+	}
+	return value
+}
+
+// name reads an anchor or alias name
+func (y *yamlParser) name() string {
+	start := y.pos
+	for !y.blankAt(y.pos) && !isFlowIndicator(y.data[y.pos]) {
+		y.pos++
+	}
+	return string(y.data[start:y.pos])
+}
+
+func (y *yamlParser) alias() (interface{}, error) {
+	start := y.pos
+	y.pos++
+	name := y.name()
+	value, ok := y.anchors[name]
+	if !ok {
+		return nil, y.errorAt(start, "unknown anchor %q", name)
+	}
+	if y.aliased += countNodes(value); y.aliased > yamlMaxAliasNodes {
+		return nil, y.errorAt(start, "aliases expand to more than %d nodes", yamlMaxAliasNodes)
+	}
+	return deepCopy(value), nil
+}
+
+func countNodes(value interface{}) int {
+	n := 1
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			n += countNodes(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			n += countNodes(item)
+		}
+	}
+	return n
+}
+
+// tag reads a node tag. Only the non-specific "!" tag and the core
+// schema tags such as "!!str" are supported.
+func (y *yamlParser) tag() (string, error) {
+	start := y.pos
+	if y.byteAt(y.pos+1) == '<' {
+		for y.pos < len(y.data) && y.data[y.pos] != '>' && !y.blankAt(y.pos) {
+			y.pos++
+		}
+		if y.peek() == '>' {
+			y.pos++
+		}
+	} else {
+		for !y.blankAt(y.pos) && !isFlowIndicator(y.data[y.pos]) {
+			y.pos++
+		}
+	}
+	tag := string(y.data[start:y.pos])
+	if name, ok := strings.CutPrefix(tag, "!<tag:yaml.org,2002:"); ok {
+		tag = "!!" + strings.TrimSuffix(name, ">")
+	}
+	switch tag {
+	case "!", "!!str", "!!int", "!!float", "!!bool", "!!null", "!!map", "!!seq":
+		return tag, nil
+	}
+	return "", y.errorAt(start, "unsupported tag %s", tag)
+}
+
+func (y *yamlParser) checkCollectionTag(value interface{}, tag string, tagPos int) error {
+	switch tag {
+	case "", "!":
+		return nil
+	case "!!map":
+		if _, ok := value.(map[string]interface{}); ok {
+			return nil
+		}
+	case "!!seq":
+		if _, ok := value.([]interface{}); ok {
+			return nil
+		}
+	}
+	return y.errorAt(tagPos, "cannot apply tag %s to a collection of this kind", tag)
+}
+
+// resolve types a scalar from its tag, or from the core schema when it
+// is plain and untagged
+func (y *yamlParser) resolve(text string, plain bool, tag string, at int) (interface{}, error) {
+	if tag == "!" || tag == "!!str" || tag == "" && !plain {
+		return text, nil
+	}
+	value, kind, err := yamlCoreValue(text)
+	if err != nil {
+		return nil, y.errorAt(at, "%v", err)
+	}
+	switch tag {
+	case "":
+		return value, nil
+	case "!!" + kind:
+		return value, nil
+	case "!!float":
+		if kind == "int" {
+			return value, nil
+		}
+	}
+	return nil, y.errorAt(at, "cannot read %q as %s", text, tag)
+}
+
+var (
+	yamlIntPattern   = regexp.MustCompile(`^(?:[-+]?[0-9]+|0o[0-7]+|0x[0-9a-fA-F]+)$`)
+	yamlFloatPattern = regexp.MustCompile(`^[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?$`)
+	yamlSpecial      = regexp.MustCompile(`^(?:[-+]?\.(?:inf|Inf|INF)|\.(?:nan|NaN|NAN))$`)
+)
+
+// yamlCoreValue types text with the YAML 1.2 core schema, reporting the
+// kind as "null", "bool", "int", "float" or "str". Infinity and NaN are
+// errors because JSON cannot represent them.
+func yamlCoreValue(text string) (interface{}, string, error) {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, "null", nil
+	case "true", "True", "TRUE":
+		return true, "bool", nil
+	case "false", "False", "FALSE":
+		return false, "bool", nil
+	}
+	switch {
+	case yamlIntPattern.MatchString(text):
+		n := new(big.Int)
+		switch {
+		case strings.HasPrefix(text, "0o"):
+			n.SetString(text[2:], 8)
+		case strings.HasPrefix(text, "0x"):
+			n.SetString(text[2:], 16)
+		default:
+			n.SetString(strings.TrimPrefix(text, "+"), 10)
+		}
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, "int", nil
+	case yamlFloatPattern.MatchString(text):
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, "float", fmt.Errorf("number %s out of range", text)
+		}
+		return f, "float", nil
+	case yamlSpecial.MatchString(text):
+		return nil, "float", fmt.Errorf("%s cannot be represented in JSON", text)
+	}
+	return text, "str", nil
+}
+
+// mapping parses a block mapping at column indent whose first key has
+// been read, with pos at its ':'
+func (y *yamlParser) mapping(indent int, key string, keyPos int) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+	for {
+		if _, dup := obj[key]; dup {
+			return nil, y.errorAt(keyPos, "duplicate key %q", key)
+		}
+		y.pos++
+		value, err := y.node(indent, true, true)
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = value
+		if err := y.endNode(); err != nil {
+			return nil, err
+		}
+		if y.pos >= len(y.data) || y.markerAt(y.pos) {
+			return obj, nil
+		}
+		col := y.column()
+		if col < indent || col == indent && y.peek() == '-' && y.blankAt(y.pos+1) {
+			return obj, nil
+		}
+		if col > indent {
+			return nil, y.errorAt(y.pos, "bad indentation of a mapping entry")
+		}
+		keyPos = y.pos
+		if key, err = y.key(indent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// key reads an implicit mapping key and moves to its ':'
+func (y *yamlParser) key(indent int) (string, error) {
+	start := y.pos
+	switch c := y.peek(); {
+	case c == '?' && y.blankAt(y.pos+1):
+		return "", y.errorAt(start, "explicit mapping keys are not supported")
+	case c == '[' || c == '{':
+		return "", y.errorAt(start, "flow collections as mapping keys are not supported")
+	case c == '*':
+		return "", y.errorAt(start, "aliases as mapping keys are not supported")
+	case c == '&' || c == '!':
+		return "", y.errorAt(start, "properties on mapping keys are not supported")
+	}
+	text, _, multiline, err := y.scalar(indent, false)
+	if err != nil {
+		return "", err
+	}
+	if !y.atKeyIndicator() {
+		return "", y.errorAt(start, "expected a mapping key followed by ':'")
+	}
+	if multiline {
+		return "", y.errorAt(start, "mapping keys must be on a single line")
+	}
+	return text, nil
+}
+
+// sequence parses a block sequence whose entries start at column indent
+func (y *yamlParser) sequence(indent int) ([]interface{}, error) {
+	arr := []interface{}{}
+	for {
+		y.pos++
+		value, err := y.node(indent, false, false)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+		if err := y.endNode(); err != nil {
+			return nil, err
+		}
+		if y.pos >= len(y.data) || y.markerAt(y.pos) {
+			return arr, nil
+		}
+		col := y.column()
+		if col < indent || col == indent && (y.peek() != '-' || !y.blankAt(y.pos+1)) {
+			return arr, nil
+		}
+		if col > indent {
+			return nil, y.errorAt(y.pos, "bad indentation of a sequence entry")
+		}
+	}
+}
+
+// scalar reads a quoted or plain scalar, reporting whether it was plain
+// and whether it spanned several lines
+func (y *yamlParser) scalar(parent int, flow bool) (text string, plain, multiline bool, err error) {
+	start := y.pos
+	switch y.peek() {
+	case '"':
+		text, err = y.doubleQuoted()
+	case '\'':
+		text, err = y.singleQuoted()
+	default:
+		if !y.plainStart(flow) {
+			return "", false, false, y.unexpected()
+		}
+		text, plain = y.plain(parent, flow), true
+	}
+	return text, plain, bytes.IndexByte(y.data[start:y.pos], '\n') >= 0, err
+}
+
+// plainStart reports whether a plain scalar can start at pos
+func (y *yamlParser) plainStart(flow bool) bool {
+	switch c := y.peek(); c {
+	case '-', '?', ':':
+		return !y.blankAt(y.pos+1) && !(flow && isFlowIndicator(y.byteAt(y.pos+1)))
+	case ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`':
+		return false
+	}
+	return !y.blankAt(y.pos)
+}
+
+// plain reads a plain scalar. Continuation lines must be indented past
+// parent, and line breaks fold to spaces.
+func (y *yamlParser) plain(parent int, flow bool) string {
+	var b strings.Builder
+	for {
+		start, end := y.pos, y.pos
+		for y.pos < len(y.data) {
+			c := y.data[y.pos]
+			if c == '\n' || c == '\r' ||
+				c == ':' && (y.blankAt(y.pos+1) || flow && isFlowIndicator(y.byteAt(y.pos+1))) ||
+				c == '#' && (y.data[y.pos-1] == ' ' || y.data[y.pos-1] == '\t') ||
+				flow && isFlowIndicator(c) {
+				break
+			}
+			y.pos++
+			if c != ' ' && c != '\t' {
+				end = y.pos
+			}
+		}
+		b.Write(y.data[start:end])
+		if c := y.peek(); c != '\n' && c != '\r' {
+			y.pos = end
+			return b.String()
+		}
+
+		i, breaks, indent, lineBegin := y.pos, 0, 0, y.pos
+		for i < len(y.data) && (y.data[i] == '\n' || y.data[i] == '\r') {
+			if y.data[i] == '\n' {
+				breaks++
+			}
+			i++
+			lineBegin = i
+			for i < len(y.data) && y.data[i] == ' ' {
+				i++
+			}
+			indent = i - lineBegin
+			for i < len(y.data) && (y.data[i] == ' ' || y.data[i] == '\t') {
+				i++
+			}
+		}
+		stop := i >= len(y.data) || y.data[i] == '#' || y.markerAt(lineBegin)
+		if flow {
+			stop = stop || isFlowIndicator(y.data[i]) || y.data[i] == ':'
+		} else {
+			stop = stop || indent <= parent
+		}
+		if stop {
+			y.pos = end
+			return b.String()
+		}
+		if breaks <= 1 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(strings.Repeat("\n", breaks-1))
+		}
+		y.pos = i
+	}
+}
+
+// fold replaces the line break at pos in a quoted scalar, along with the
+// whitespace around it: a single break becomes a space and each further
+// empty line a newline. ws is where trailing whitespace starts in buf.
+func (y *yamlParser) fold(buf []byte, ws int) []byte {
+	if ws >= 0 {
+		buf = buf[:ws]
+	}
+	breaks := 0
+	for ; y.pos < len(y.data) && y.blankAt(y.pos); y.pos++ {
+		if y.data[y.pos] == '\n' {
+			breaks++
+		}
+	}
+	if breaks <= 1 {
+		return append(buf, ' ')
+	}
+	return append(buf, strings.Repeat("\n", breaks-1)...)
+}
+
+func (y *yamlParser) singleQuoted() (string, error) {
+	start := y.pos
+	y.pos++
+	var buf []byte
+	ws := -1
+	for y.pos < len(y.data) {
+		switch c := y.data[y.pos]; c {
+		case '\'':
+			if y.byteAt(y.pos+1) != '\'' {
+				y.pos++
+				return string(buf), nil
+			}
+			buf, ws = append(buf, '\''), -1
+			y.pos += 2
+		case '\n', '\r':
+			buf, ws = y.fold(buf, ws), -1
+		case ' ', '\t':
+			if ws < 0 {
+				ws = len(buf)
+			}
+			buf = append(buf, c)
+			y.pos++
+		default:
+			buf, ws = append(buf, c), -1
+			y.pos++
+		}
+	}
+	return "", y.errorAt(start, "unterminated quoted string")
+}
+
+var yamlEscapes = map[byte]rune{
+	'0': 0, 'a': '\a', 'b': '\b', 't': '\t', '\t': '\t', 'n': '\n', 'v': '\v', 'f': '\f',
+	'r': '\r', 'e': 0x1b, ' ': ' ', '"': '"', '/': '/', '\\': '\\',
+	'N': 0x85, '_': 0xa0, 'L': 0x2028, 'P': 0x2029,
+}
+
+func (y *yamlParser) doubleQuoted() (string, error) {
+	start := y.pos
+	y.pos++
+	var buf []byte
+	ws := -1
+	for y.pos < len(y.data) {
+		switch c := y.data[y.pos]; c {
+		case '"':
+			y.pos++
+			return string(buf), nil
+		case '\\':
+			y.pos++
+			if e := y.peek(); e == '\n' || e == '\r' {
+				// an escaped line break joins the lines without a space
+				if e == '\r' {
+					y.pos++
+				}
+				y.pos++
+				n := len(buf)
+				buf = y.fold(buf, -1)
+				if buf[n] == ' ' {
+					buf = buf[:n]
+				} else {
+					buf = append(buf, '\n')
+				}
+				ws = -1
+				continue
+			}
+			r, err := y.escape()
+			if err != nil {
+				return "", err
+			}
+			buf, ws = utf8.AppendRune(buf, r), -1
+		case '\n', '\r':
+			buf, ws = y.fold(buf, ws), -1
+		case ' ', '\t':
+			if ws < 0 {
+				ws = len(buf)
+			}
+			buf = append(buf, c)
+			y.pos++
+		default:
+			buf, ws = append(buf, c), -1
+			y.pos++
+		}
+	}
+	return "", y.errorAt(start, "unterminated quoted string")
+}
+
+// escape decodes the escape sequence after a backslash, combining
+// UTF-16 surrogate pairs written as two \u escapes
+func (y *yamlParser) escape() (rune, error) {
+	start := y.pos - 1
+	e := y.peek()
+	if r, ok := yamlEscapes[e]; ok {
+		y.pos++
+		return r, nil
+	}
+	size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+	if size == 0 || y.pos+1+size > len(y.data) {
+		return 0, y.errorAt(start, "invalid escape sequence")
+	}
+	n, err := strconv.ParseUint(string(y.data[y.pos+1:y.pos+1+size]), 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return 0, y.errorAt(start, "invalid escape sequence")
+	}
+	y.pos += 1 + size
+	r := rune(n)
+	if !utf16.IsSurrogate(r) {
+		return r, nil
+	}
+	if e == 'u' && y.peek() == '\\' && y.byteAt(y.pos+1) == 'u' && y.pos+6 <= len(y.data) {
+		if low, err := strconv.ParseUint(string(y.data[y.pos+2:y.pos+6]), 16, 32); err == nil {
+			if combined := utf16.DecodeRune(r, rune(low)); combined != utf8.RuneError {
+				y.pos += 6
+				return combined, nil
+			}
+		}
+	}
+	return utf8.RuneError, nil
+}
+
+// blockScalar reads a literal (|) or folded (>) block scalar inside a
+// collection at column parent
+func (y *yamlParser) blockScalar(parent int) (string, error) {
+	folded := y.peek() == '>'
+	y.pos++
+	var chomp byte
+	indent := -1
+	for i := 0; i < 2; i++ {
+		switch c := y.peek(); {
+		case (c == '+' || c == '-') && chomp == 0:
+			chomp = c
+			y.pos++
+		case c >= '1' && c <= '9' && indent < 0:
+			indent = max(parent, 0) + int(c-'0')
+			y.pos++
+		}
+	}
+	for y.peek() == ' ' || y.peek() == '\t' {
+		y.pos++
+	}
+	if y.peek() == '#' && y.blankAt(y.pos-1) {
+		for y.pos < len(y.data) && y.data[y.pos] != '\n' {
+			y.pos++
+		}
+	}
+	if y.peek() == '\r' {
+		y.pos++
+	}
+	if y.pos < len(y.data) && y.data[y.pos] != '\n' {
+		return "", y.errorAt(y.pos, "invalid block scalar header")
+	}
+	y.pos++
+
+	// lines holds content lines without their indentation, and "" for
+	// empty lines
+	var lines []string
+	lastBreak := false
+	for y.pos < len(y.data) && !y.markerAt(y.pos) {
+		eol := bytes.IndexByte(y.data[y.pos:], '\n')
+		if eol < 0 {
+			eol = len(y.data)
+		} else {
+			eol += y.pos
+		}
+		line := strings.TrimSuffix(string(y.data[y.pos:eol]), "\r")
+		spaces := len(line) - len(strings.TrimLeft(line, " "))
+		blank := strings.TrimLeft(line, " \t") == ""
+		if indent < 0 && !blank {
+			if spaces <= parent {
+				break
+			}
+			indent = spaces
+		}
+		switch {
+		case indent >= 0 && spaces >= indent && len(line) > indent:
+			lines = append(lines, line[indent:])
+			lastBreak = eol < len(y.data)
+		case blank:
+			lines = append(lines, "")
+		default:
+			return y.joinBlock(lines, folded, chomp, lastBreak), nil
+		}
+		y.pos = min(eol+1, len(y.data))
+	}
+	return y.joinBlock(lines, folded, chomp, lastBreak), nil
+}
+
+// joinBlock assembles the lines of a block scalar, folding them when
+// folded is set and applying the chomping indicator to trailing breaks
+func (y *yamlParser) joinBlock(lines []string, folded bool, chomp byte, lastBreak bool) string {
+	last := len(lines) - 1
+	for last >= 0 && lines[last] == "" {
+		last--
+	}
+	var b strings.Builder
+	moreIndented := func(s string) bool { return s[0] == ' ' || s[0] == '\t' }
+	empty, prev := 0, ""
+	for _, line := range lines[:last+1] {
+		switch {
+		case line == "":
+			empty++
+			continue
+		case prev == "":
+			b.WriteString(strings.Repeat("\n", empty))
+		case folded && !moreIndented(prev) && !moreIndented(line):
+			if empty == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteString(strings.Repeat("\n", empty))
+			}
+		default:
+			b.WriteString(strings.Repeat("\n", empty+1))
+		}
+		b.WriteString(line)
+		empty, prev = 0, line
+	}
+
+	trailing := len(lines) - 1 - last
+	switch {
+	case chomp == '-':
+	case last < 0:
+		if chomp == '+' {
+			b.WriteString(strings.Repeat("\n", trailing))
+		}
+	case chomp == '+':
+		b.WriteString(strings.Repeat("\n", trailing+1))
+	case lastBreak:
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// flow parses a flow sequence or mapping
+func (y *yamlParser) flow() (interface{}, error) {
+	if y.depth++; y.depth > defaultMaxDepth {
+		return nil, y.errorAt(y.pos, "exceeded max depth of %d", defaultMaxDepth)
+	}
+	defer func() { y.depth-- }()
+
+	start := y.pos
+	var arr []interface{}
+	var obj map[string]interface{}
+	closing := byte('}')
+	if y.data[y.pos] == '[' {
+		arr, closing = []interface{}{}, ']'
+	} else {
+		obj = make(map[string]interface{})
+	}
+	y.pos++
+
+	for {
+		y.skipFlowBlank()
+		if y.pos >= len(y.data) {
+			return nil, y.errorAt(start, "unterminated flow collection")
+		}
+		if y.peek() == closing {
+			y.pos++
+			if obj != nil {
+				return obj, nil
+			}
+			return arr, nil
+		}
+		if y.peek() == '?' && y.blankAt(y.pos+1) {
+			return nil, y.errorAt(y.pos, "explicit mapping keys are not supported")
+		}
+
+		keyPos := y.pos
+		value, key, scalar, err := y.flowNode()
+		if err != nil {
+			return nil, err
+		}
+		y.skipFlowBlank()
+		isPair := y.peek() == ':'
+		if isPair {
+			y.pos++
+			y.skipFlowBlank()
+			value = nil
+			if c := y.peek(); c != ',' && c != closing {
+				if value, _, _, err = y.flowNode(); err != nil {
+					return nil, err
+				}
+				y.skipFlowBlank()
+			}
+		}
+		if (isPair || obj != nil) && !scalar {
+			return nil, y.errorAt(keyPos, "mapping keys must be scalars")
+		}
+		switch {
+		case obj != nil:
+			if _, dup := obj[key]; dup {
+				return nil, y.errorAt(keyPos, "duplicate key %q", key)
+			}
+			if !isPair {
+				value = nil
+			}
+			obj[key] = value
+		case isPair:
+			arr = append(arr, map[string]interface{}{key: value})
+		default:
+			arr = append(arr, value)
+		}
+
+		if y.peek() == ',' {
+			y.pos++
+		} else if y.pos < len(y.data) && y.peek() != closing {
+			return nil, y.unexpected()
+		}
+	}
+}
+
+// flowNode parses a node inside a flow collection, returning the source
+// text of scalars for use as mapping keys
+func (y *yamlParser) flowNode() (value interface{}, text string, scalar bool, err error) {
+	var anchor, tag string
+	tagPos := 0
+	for c := y.peek(); c == '&' || c == '!'; c = y.peek() {
+		if c == '&' {
+			y.pos++
+			anchor = y.name()
+		} else {
+			tagPos = y.pos
+			if tag, err = y.tag(); err != nil {
+				return nil, "", false, err
+			}
+		}
+		y.skipFlowBlank()
+	}
+
+	start := y.pos
+	switch c := y.peek(); {
+	case c == '*':
+		if anchor != "" || tag != "" {
+			return nil, "", false, y.errorAt(start, "an alias cannot have an anchor or tag")
+		}
+		value, err = y.alias()
+		return value, "", false, err
+	case c == '[' || c == '{':
+		if value, err = y.flow(); err == nil {
+			err = y.checkCollectionTag(value, tag, tagPos)
+		}
+	case isFlowIndicator(c) || c == ':' && (anchor != "" || tag != ""):
+		if anchor == "" && tag == "" {
+			return nil, "", false, y.unexpected()
+		}
+		value, err = y.resolve("", true, tag, tagPos)
+		scalar = true
+	default:
+		var plain bool
+		if text, plain, _, err = y.scalar(-1, true); err != nil {
+			return nil, "", false, err
+		}
+		if tag == "" {
+			tagPos = start
+		}
+		value, err = y.resolve(text, plain, tag, tagPos)
+		scalar = true
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+	return y.anchor(anchor, value), text, scalar, nil
+}
+
+// DumpYAML converts the JSONValue to a YAML document. Object keys are
+// sorted like Dumps, strings are quoted only where YAML would read them
+// as another type, and multi-line strings use literal block scalars.
+func (jv *JSONValue) DumpYAML() ([]byte, error) {
+	return DumpYAMLAll(jv)
+}
+
+// DumpYAMLAll writes docs as a YAML stream, separating the documents
+// with "---" lines
+func DumpYAMLAll(docs ...*JSONValue) ([]byte, error) {
+	e := &yamlEmitter{opts: encodeOptions{floatPrec: -1}}
+	for i, doc := range docs {
+		if i > 0 {
+			e.buf = append(e.buf, "---\n"...)
+		}
+		if err := e.document(doc); err != nil {
+			return nil, err
+		}
+	}
+	return e.buf, nil
+}
+
+type yamlEmitter struct {
+	buf  []byte
+	opts encodeOptions
+}
+
+func (e *yamlEmitter) indent(n int) {
+	for i := 0; i < n; i++ {
+		e.buf = append(e.buf, ' ')
+	}
+}
+
+// value converts a Go value outside the JSONValue data model through its
+// encoding/json form
+func (e *yamlEmitter) value(value interface{}) (interface{}, error) {
+	switch v := unwrap(value).(type) {
+	case nil, bool, string, map[string]interface{}, []interface{}:
+		return v, nil
+	default:
+		if _, ok, _ := e.opts.appendScalar(nil, v); ok {
+			return v, nil
+		}
+		return genericValue(v)
+	}
+}
+
+func (e *yamlEmitter) document(doc interface{}) error {
+	value, err := e.value(doc)
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			return e.mapping(v, 0, true)
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return e.sequence(v, 0, true)
+		}
+	}
+	return e.scalar(value, 2)
+}
+
+// mapping writes obj's entries at column indent. When inline is set the
+// first entry goes at the current position, such as after "- ".
+func (e *yamlEmitter) mapping(obj map[string]interface{}, indent int, inline bool) error {
+	for i, k := range sortedKeys(obj) {
+		if i > 0 || !inline {
+			e.indent(indent)
+		}
+		if yamlPlain(k) {
+			e.buf = append(e.buf, k...)
+		} else {
+			e.buf = appendYAMLQuoted(e.buf, k)
+		}
+		e.buf = append(e.buf, ':')
+		if err := e.child(obj[k], indent, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sequence writes arr's entries at column indent, the first at the
+// current position when inline is set
+func (e *yamlEmitter) sequence(arr []interface{}, indent int, inline bool) error {
+	for i, item := range arr {
+		if i > 0 || !inline {
+			e.indent(indent)
+		}
+		e.buf = append(e.buf, '-')
+		if err := e.child(item, indent, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// child writes a mapping value or sequence entry after its indicator.
+// Collections in a sequence start on the entry's line.
+func (e *yamlEmitter) child(value interface{}, indent int, entry bool) error {
+	value, err := e.value(value)
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			if entry {
+				e.buf = append(e.buf, ' ')
+				return e.mapping(v, indent+2, true)
+			}
+			e.buf = append(e.buf, '\n')
+			return e.mapping(v, indent+2, false)
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			if entry {
+				e.buf = append(e.buf, ' ')
+				return e.sequence(v, indent+2, true)
+			}
+			e.buf = append(e.buf, '\n')
+			return e.sequence(v, indent+2, false)
+		}
+	}
+	e.buf = append(e.buf, ' ')
+	return e.scalar(value, indent+2)
+}
+
+// scalar writes a scalar or empty collection and ends the line. Block
+// scalar content is indented to column indent.
+func (e *yamlEmitter) scalar(value interface{}, indent int) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		e.buf = append(e.buf, "{}"...)
+	case []interface{}:
+		e.buf = append(e.buf, "[]"...)
+	case string:
+		switch {
+		case yamlPlain(v):
+			e.buf = append(e.buf, v...)
+		case yamlLiteral(v):
+			e.literal(v, indent)
+			return nil
+		default:
+			e.buf = appendYAMLQuoted(e.buf, v)
+		}
+	default:
+		buf, _, err := e.opts.appendScalar(e.buf, v)
+		if err != nil {
+			return err
+		}
+		e.buf = buf
+	}
+	e.buf = append(e.buf, '\n')
+	return nil
+}
+
+// literal writes s as a literal block scalar
+func (e *yamlEmitter) literal(s string, indent int) {
+	e.buf = append(e.buf, '|')
+	if trimmed, ok := strings.CutSuffix(s, "\n"); ok {
+		s = trimmed
+	} else {
+		e.buf = append(e.buf, '-')
+	}
+	e.buf = append(e.buf, '\n')
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			e.indent(indent)
+			e.buf = append(e.buf, line...)
+		}
+		e.buf = append(e.buf, '\n')
+	}
+}
+
+// yamlNumberLike matches strings that YAML 1.1 readers may take for
+// numbers, dates or times, such as 1_000, 0b101 and 2024-01-02
+var yamlNumberLike = regexp.MustCompile(`^[-+.]?[0-9][0-9_:.+\-eExXoObB]*$`)
+
+// yamlPlain reports whether s can be written as a plain scalar and read
+// back as the same string
+func yamlPlain(s string) bool {
+	if s == "" || s[0] == ' ' || s[len(s)-1] == ' ' || s[len(s)-1] == ':' ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.HasPrefix(s, "...") || !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	if _, kind, err := yamlCoreValue(s); kind != "str" || err != nil {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "on", "off":
+		return false
+	}
+	return !yamlNumberLike.MatchString(s)
+}
+
+// yamlLiteral reports whether s can be written as a literal block scalar
+func yamlLiteral(s string) bool {
+	if !strings.Contains(s, "\n") || s[0] == ' ' || s[0] == '\n' ||
+		strings.HasSuffix(s, "\n\n") || !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// appendYAMLQuoted writes s as a double-quoted YAML scalar, escaping
+// characters YAML does not allow to appear literally
+func appendYAMLQuoted(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, `\uFFFD`...)
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, `\n`...)
+		case r == '\t':
+			buf = append(buf, `\t`...)
+		case r == '\r':
+			buf = append(buf, `\r`...)
+		case r < 0x20 || r >= 0x7f && r <= 0x9f:
+			buf = fmt.Appendf(buf, `\x%02X`, r)
+		case r == 0x2028 || r == 0x2029 || r == 0xfeff || r == 0xfffe || r == 0xffff:
+			buf = fmt.Appendf(buf, `\u%04X`, r)
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
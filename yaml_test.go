@@ -0,0 +1,273 @@
+package easyjson
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLoadYAML(t *testing.T) {
+	src := `# service config
+name: app
+version: 1.20
+count: 3
+enabled: true
+empty:
+nothing: ~
+hex: 0x1F
+octal: 0o17
+quoted: "1"
+single: 'it''s'
+url: http://example.com/a#frag
+list:
+  - a
+  - -2
+  - - nested
+    - seq
+inline: [1, "two", {three: 3}, [], {}]
+map: {a: 1, "b": [x, y], c}
+compact:
+- name: x
+  value: 1
+-
+  name: y
+- - deep
+plain: this is
+  folded plain
+
+  text
+literal: |
+  line 1
+    indented
+  line 3
+folded: >-
+  a
+  b
+
+  c
+"key with: colon": 'v'
+`
+	jv, err := LoadYAML([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"compact":[{"name":"x","value":1},{"name":"y"},["deep"]],"count":3,"empty":null,"enabled":true,` +
+		`"folded":"a b\nc","hex":31,"inline":[1,"two",{"three":3},[],{}],"key with: colon":"v",` +
+		`"list":["a",-2,["nested","seq"]],"literal":"line 1\n  indented\nline 3\n",` +
+		`"map":{"a":1,"b":["x","y"],"c":null},"name":"app","nothing":null,"octal":15,` +
+		`"plain":"this is folded plain\ntext","quoted":"1","single":"it's","url":"http://example.com/a#frag","version":1.2}`
+	if got := dumps(t, jv); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestLoadYAMLScalars(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{`"tab\tuni\u00e9\x41\U0001F600\ud83d\ude00"`, `"tab\tuniéA😀😀"`},
+		{"\"folded\n  line\n\n  para\\\n  joined\"", `"folded line\nparajoined"`},
+		{"'single\n\n  fold'", `"single\nfold"`},
+		{"!!str 123", `"123"`},
+		{"!!float 1", `1`},
+		{"! true", `"true"`},
+		{"!<tag:yaml.org,2002:bool> True", `true`},
+		{"!!null", `null`},
+		{"-.5e3", `-500`},
+		{"+12", `12`},
+		{"007", `7`},
+		{"1_000", `"1_000"`},
+		{"yes", `"yes"`},
+		{"--- |+\n  keep\n\n", `"keep\n\n"`},
+		{"--- |-\n  strip\n\n", `"strip"`},
+		{"--- >\n  fold\n  this\n\n    more\n  back\n", `"fold this\n\n  more\nback\n"`},
+		{"a: |2\n   lead\n  x\n", `{"a":" lead\nx\n"}`},
+		{"a: |\n  no break", `{"a":"no break"}`},
+		{"[a: 1, b]", `[{"a":1},"b"]`},
+		{`{"json":1,"k":[true,null]}`, `{"json":1,"k":[true,null]}`},
+		{"{a: [1,\n  2], b:\n  c}", `{"a":[1,2],"b":"c"}`},
+		{"", `null`},
+		{"# only a comment\n", `null`},
+	}
+	for _, tt := range tests {
+		jv, err := LoadYAML([]byte(tt.src))
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+			continue
+		}
+		if got := dumps(t, jv); got != tt.want {
+			t.Errorf("%q: expected %s, got %s", tt.src, tt.want, got)
+		}
+	}
+}
+
+func TestLoadYAMLAnchors(t *testing.T) {
+	jv, err := LoadYAML([]byte("base: &base\n  x: 1\ncopy: *base\nlist: [&v 5, *v]\nseq: &s\n- 1\nagain: *s\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dumps(t, jv); got != `{"again":[1],"base":{"x":1},"copy":{"x":1},"list":[5,5],"seq":[1]}` {
+		t.Errorf("Got %s", got)
+	}
+	jv.Get("copy").Set("x", 2)
+	if jv.Q("base", "x").AsInt() != 1 {
+		t.Error("Expected aliases to be copies")
+	}
+
+	bomb := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for _, name := range "bcdefgh" {
+		prev := string(rune(name - 1))
+		bomb += string(name) + ": &" + string(name) + " [*" + prev + strings.Repeat(", *"+prev, 9) + "]\n"
+	}
+	if _, err := LoadYAML([]byte(bomb)); err == nil || !strings.Contains(err.Error(), "aliases expand") {
+		t.Errorf("Expected alias expansion error, got %v", err)
+	}
+}
+
+func TestLoadYAMLAll(t *testing.T) {
+	src := "%YAML 1.2\n---\na: 1\n...\n---\n- 2\n--- 3\n---\n"
+	docs, err := LoadYAMLAll([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, doc := range docs {
+		got = append(got, dumps(t, doc))
+	}
+	if s := strings.Join(got, " "); s != `{"a":1} [2] 3 null` {
+		t.Errorf("Got %s", s)
+	}
+	if _, err := LoadYAML([]byte(src)); err == nil {
+		t.Error("Expected error for a multi-document stream")
+	}
+	if docs, err := LoadYAMLAll(nil); err != nil || len(docs) != 0 {
+		t.Errorf("Expected no documents, got %v, %v", docs, err)
+	}
+}
+
+func TestLoadYAMLErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+		msg  string
+	}{
+		{"a: 1\n\tb: 2\n", 2, "tabs"},
+		{"a:\n  b: 1\n   c: 2\n", 3, "not allowed"},
+		{"a:\n  b: 1\n c: 2\n", 3, "bad indentation"},
+		{"a: 1\na: 2\n", 2, "duplicate key"},
+		{"a: *nope\n", 1, "unknown anchor"},
+		{"a: \"open\n", 1, "unterminated"},
+		{"a: .inf\n", 1, "cannot be represented"},
+		{"a: !custom x\n", 1, "unsupported tag"},
+		{"a: b: c\n", 1, "not allowed"},
+		{"? a\n: b\n", 1, "explicit"},
+		{"a:\n  - x\n  y: 1\n", 3, "bad indentation"},
+		{"a: [1]x\n", 1, "unexpected"},
+		{"[1, 2\n", 1, "unterminated"},
+		{"!!int x\n", 1, "cannot read"},
+		{"x: \"\\q\"\n", 1, "escape"},
+		{"%YAML 1.2\na: 1\n", 2, "expected '---'"},
+	}
+	for _, tt := range tests {
+		_, err := LoadYAML([]byte(tt.src))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected *SyntaxError, got %v", tt.src, err)
+			continue
+		}
+		if syntaxErr.Pos.Line != tt.line || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%q: expected %q on line %d, got %v", tt.src, tt.msg, tt.line, err)
+		}
+	}
+}
+
+func TestDumpYAML(t *testing.T) {
+	jv := mustLoads(t, `{"name": "app", "tags": ["a", "true", ""], "nested": {"list": [{"x": 1, "y": [1, 2]}, [3]], "empty": {}, "none": []},
+		"text": "line 1\nline 2\n", "n": null, "f": 1.5, "key: odd": "#hash"}`)
+	out, err := jv.DumpYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `f: 1.5
+"key: odd": "#hash"
+"n": null
+name: app
+nested:
+  empty: {}
+  list:
+    - x: 1
+      "y":
+        - 1
+        - 2
+    - - 3
+  none: []
+tags:
+  - a
+  - "true"
+  - ""
+text: |
+  line 1
+  line 2
+`
+	if string(out) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, out)
+	}
+
+	out, err = DumpYAMLAll(New(1), mustLoads(t, `{}`), New("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "1\n---\n{}\n---\nx\n" {
+		t.Errorf("Got %q", out)
+	}
+	if _, err := New(math.Inf(1)).DumpYAML(); err == nil {
+		t.Error("Expected error for infinity")
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	strs := []string{"", " lead", "trail ", "a: b", "a:", "#x", "x #y", "-", "- x", "? x", "[x]", "{x}", "*x", "&x", "!x",
+		"'q'", `"q"`, "true", "False", "null", "~", "1", "1.0", "-1e3", ".inf", ".nan", "0x1F", "0o7", "yes", "off", "1_000",
+		"2024-01-02", "12:30", "---", "...", "multi\nline\n", "multi\nline", "two\n\n", "\nlead", "  \n", "tab\there", "a\r\nb",
+		"bell\a", "del\x7f", "ls\u2028", "bom\ufeff", "é ü 😀", "%x", "@x", "`x", "a\n  indented\n\nblank", "x\n---\ny"}
+	var items []interface{}
+	obj := map[string]interface{}{}
+	for _, s := range strs {
+		items = append(items, s)
+		obj[s] = s
+	}
+	docs := []*JSONValue{
+		New(items),
+		New(obj),
+		mustLoads(t, `[[], {}, [[]], [{}], {"a": [[1, {"b": null}]]}, -0.000001, 1e21, 123456789012]`),
+		New("root\nstring\n"),
+		New(nil),
+		mustLoads(t, `[{"a": "x\ny", "b": [1]}]`),
+	}
+	for _, doc := range docs {
+		out, err := doc.DumpYAML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := LoadYAML(out)
+		if err != nil {
+			t.Errorf("Reading back\n%s\nfailed: %v", out, err)
+			continue
+		}
+		if !back.Equal(doc, EqualOptions{}) {
+			t.Errorf("Round trip of %s gave %s via\n%s", dumps(t, doc), dumps(t, back), out)
+		}
+	}
+
+	out, _ := DumpYAMLAll(docs...)
+	back, err := LoadYAMLAll(out)
+	if err != nil || len(back) != len(docs) {
+		t.Fatalf("Expected %d documents, got %d, %v", len(docs), len(back), err)
+	}
+	for i := range docs {
+		if !back[i].Equal(docs[i], EqualOptions{}) {
+			t.Errorf("Document %d: got %s", i, dumps(t, back[i]))
+		}
+	}
+}